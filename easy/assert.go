@@ -0,0 +1,237 @@
+package easy
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The part of an HTTP response that assertions look at.
+// Response and MockHandler both implement it, so that they share one set of checks.
+type Result interface {
+	statusCode() int
+	header() http.Header
+	bodyBytes() []byte
+	request() *http.Request
+	dumpSettings() DumpSettings
+}
+
+func checkStatus(t testing.TB, r Result, status int) {
+	t.Helper()
+	t = withDump(t, r)
+
+	require.Equal(t, status, r.statusCode(), "unexpected status code")
+}
+
+func checkEqBody(t testing.TB, r Result, body string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	require.Equal(t, body, string(r.bodyBytes()))
+}
+
+func checkEqJson(t testing.TB, r Result, obj any, opts []JsonOption) {
+	t.Helper()
+	t = withDump(t, r)
+
+	comparer, err := newJsonComparer(opts)
+	require.NoError(t, err)
+
+	diffs, err := comparer.diff(obj, r.bodyBytes())
+	require.NoError(t, err)
+
+	if len(diffs) > 0 {
+		require.Fail(t, formatJsonDiff(diffs))
+	}
+}
+
+func checkEqHeader(t testing.TB, r Result, key string, value string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	values := headerValues(r.header(), key)
+	require.NotEmpty(t, values, "header %s is not set", key)
+
+	require.Equal(t, value, values[0], "header %s", key)
+}
+
+func checkEqHeaderValues(t testing.TB, r Result, key string, values []string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	require.Equal(t, values, headerValues(r.header(), key), "header %s", key)
+}
+
+func checkContainsHeader(t testing.TB, r Result, key string, substr string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	values := headerValues(r.header(), key)
+	require.NotEmpty(t, values, "header %s is not set", key)
+
+	require.Contains(t, values[0], substr, "header %s", key)
+}
+
+func checkMatchHeader(t testing.TB, r Result, key string, pattern string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	values := headerValues(r.header(), key)
+	require.NotEmpty(t, values, "header %s is not set", key)
+
+	reg, err := regexp.Compile(pattern)
+	require.NoError(t, err)
+
+	require.Regexp(t, reg, values[0], "header %s", key)
+}
+
+func checkHasHeader(t testing.TB, r Result, key string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	require.NotEmpty(t, headerValues(r.header(), key), "header %s is not set", key)
+}
+
+func checkNoHeader(t testing.TB, r Result, key string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	values := headerValues(r.header(), key)
+	require.Empty(t, values, "header %s is set", key)
+}
+
+func checkEqJsonPath(t testing.TB, r Result, path string, value any, opts []JsonOption) {
+	t.Helper()
+	t = withDump(t, r)
+
+	diffs := diffJsonPath(t, r, path, value, opts)
+	if len(diffs) > 0 {
+		require.Fail(t, formatJsonDiff(diffs))
+	}
+}
+
+// Returns differences between the value at the path and the expected value.
+func diffJsonPath(t testing.TB, r Result, path string, value any, opts []JsonOption) []string {
+	t.Helper()
+
+	segments, matches := selectJsonPath(t, r, path)
+
+	comparer, err := newJsonComparer(opts)
+	require.NoError(t, err)
+
+	var diffs []string
+	if isIndefinitePath(segments) {
+		values := make([]any, len(matches))
+		for i, m := range matches {
+			values[i] = m.value
+		}
+		diffs, err = comparer.diffValue(segments, value, values)
+	} else {
+		require.NotEmpty(t, matches, "json path %s is not found", path)
+		diffs, err = comparer.diffValue(matches[0].path, value, matches[0].value)
+	}
+	require.NoError(t, err)
+
+	return diffs
+}
+
+func checkJsonPathType(t testing.TB, r Result, path string, typ JsonType) {
+	t.Helper()
+	t = withDump(t, r)
+
+	_, matches := selectJsonPath(t, r, path)
+	require.NotEmpty(t, matches, "json path %s is not found", path)
+
+	for _, m := range matches {
+		require.Equal(t, typ, jsonTypeOf(m.value), "type of %s", formatJsonPath(m.path))
+	}
+}
+
+func checkJsonPathLen(t testing.TB, r Result, path string, length int) {
+	t.Helper()
+	t = withDump(t, r)
+
+	segments, matches := selectJsonPath(t, r, path)
+
+	if isIndefinitePath(segments) {
+		require.Len(t, matches, length, "number of values selected by %s", path)
+		return
+	}
+
+	require.NotEmpty(t, matches, "json path %s is not found", path)
+
+	switch v := matches[0].value.(type) {
+	case []any:
+		require.Len(t, v, length, "length of %s", path)
+	case map[string]any:
+		require.Len(t, v, length, "length of %s", path)
+	case string:
+		require.Equal(t, length, utf8.RuneCountInString(v), "length of %s", path)
+	default:
+		require.Fail(t, fmt.Sprintf("%s is %s and has no length", path, jsonTypeOf(v)))
+	}
+}
+
+func checkHasJsonPath(t testing.TB, r Result, path string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	_, matches := selectJsonPath(t, r, path)
+	require.NotEmpty(t, matches, "json path %s is not found", path)
+}
+
+func checkNoJsonPath(t testing.TB, r Result, path string) {
+	t.Helper()
+	t = withDump(t, r)
+
+	_, matches := selectJsonPath(t, r, path)
+	require.Empty(t, matches, "json path %s exists", path)
+}
+
+// Parses the path and selects values from the json body.
+func selectJsonPath(t testing.TB, r Result, path string) ([]pathSegment, []jsonMatch) {
+	t.Helper()
+
+	segments, err := parsePath(path)
+	require.NoError(t, err)
+
+	doc, err := decodeJson(r.bodyBytes())
+	require.NoError(t, err, "response body is not json")
+
+	return segments, selectJson(doc, segments)
+}
+
+// Returns all values of the header.
+//
+// The key is looked up in canonical form first (like http.Header.Values),
+// and then any key that differs only in case is appended in sorted order.
+// That way headers written directly into the map without canonicalization
+// (e.g. `w.Header()["x-id"] = ...`) are also found.
+func headerValues(h http.Header, key string) []string {
+	canonical := textproto.CanonicalMIMEHeaderKey(key)
+	values := append([]string{}, h[canonical]...)
+
+	others := []string{}
+	for k := range h {
+		if k != canonical && strings.EqualFold(k, key) {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+
+	for _, k := range others {
+		values = append(values, h[k]...)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
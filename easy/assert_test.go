@@ -0,0 +1,90 @@
+package easy_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertFailures(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 404,
+
+		Header: http.Header{
+			"Content-Type": []string{"text/plain"},
+		},
+	}
+	r := easy.NewResponse(resp)
+
+	t.Run("Status", func(t *testing.T) {
+		msg := expectFail(t, func(t testing.TB) {
+			r.Ok(t)
+		})
+		require.Contains(t, msg, "unexpected status code")
+	})
+
+	t.Run("EqHeader", func(t *testing.T) {
+		msg := expectFail(t, func(t testing.TB) {
+			r.EqHeader(t, "Content-Type", "application/json")
+		})
+		require.Contains(t, msg, "header Content-Type")
+
+		msg = expectFail(t, func(t testing.TB) {
+			r.EqHeader(t, "Location", "/")
+		})
+		require.Contains(t, msg, "header Location is not set")
+	})
+
+	t.Run("NoHeader", func(t *testing.T) {
+		expectFail(t, func(t testing.TB) {
+			r.NoHeader(t, "content-type")
+		})
+	})
+}
+
+func TestJsonFailures(t *testing.T) {
+	t.Run("diff", func(t *testing.T) {
+		msg := expectFail(t, func(t testing.TB) {
+			r := newJsonResponse(`{"items": [{"id": 1}], "extra": true}`)
+			r.EqJson(t, map[string]any{"items": []any{map[string]any{"id": 2}}, "name": "x"})
+		})
+
+		require.Contains(t, msg, `$.items[0].id: expected 2, got 1`)
+		require.Contains(t, msg, `$.name: missing, expected "x"`)
+		require.Contains(t, msg, `$.extra: unexpected field true`)
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		msg := expectFail(t, func(t testing.TB) {
+			r := newJsonResponse(`aaaa`)
+			r.EqJson(t, "aaaa")
+		})
+
+		require.Contains(t, msg, "response body is not json")
+	})
+
+	t.Run("json path not found", func(t *testing.T) {
+		msg := expectFail(t, func(t testing.TB) {
+			r := newJsonResponse(`{"items": []}`)
+			r.EqJsonPath(t, "$.items[0]", 1)
+		})
+
+		require.Contains(t, msg, "json path $.items[0] is not found")
+	})
+
+	t.Run("DisallowUnknownFields", func(t *testing.T) {
+		expectFail(t, func(t testing.TB) {
+			r := newJsonResponse(`{"nya": "aaaa", "other": 1}`)
+			easy.Json[JsonData](t, r, easy.DisallowUnknownFields())
+		})
+	})
+
+	t.Run("RequireJsonContentType", func(t *testing.T) {
+		expectFail(t, func(t testing.TB) {
+			r := newJsonResponse(`{"nya": "aaaa"}`)
+			easy.Json[JsonData](t, r, easy.RequireJsonContentType())
+		})
+	})
+}
@@ -0,0 +1,322 @@
+package easy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Chainable assertions of Response or MockHandler.
+//
+// Created by Require, it stops the test at the first failure like the other assertions.
+// Created by Assert, it is soft: failures are collected instead of stopping the test,
+// and reported together by Done or at the end of the test.
+//
+// Example:
+//
+//	resp.Assert(t).
+//		Ok().
+//		EqHeader("Content-Type", "application/json").
+//		EqJsonPath("$.name", "cateiru").
+//		Done()
+type Assertion struct {
+	t    testing.TB
+	r    Result
+	soft bool
+
+	mu       sync.Mutex
+	failures []string
+}
+
+// Raised by FailNow of softT, and recovered by Assertion.runSoft.
+var errSoftFailNow = errors.New("soft assertion failed")
+
+func newAssertion(t testing.TB, r Result, soft bool) *Assertion {
+	a := &Assertion{
+		t:    t,
+		r:    r,
+		soft: soft,
+	}
+
+	if soft {
+		t.Cleanup(a.Done)
+	}
+
+	return a
+}
+
+// Check if request success
+func (c *Assertion) Ok() *Assertion {
+	c.t.Helper()
+
+	return c.Status(200)
+}
+
+// Check response status code
+func (c *Assertion) Status(status int) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkStatus(t, c.r, status)
+	})
+}
+
+// Compare response body
+func (c *Assertion) EqBody(body string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkEqBody(t, c.r, body)
+	})
+}
+
+// Compare response body written json
+func (c *Assertion) EqJson(obj any, opts ...JsonOption) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkEqJson(t, c.r, obj, opts)
+	})
+}
+
+// Check the response json contains at least the fields of obj
+func (c *Assertion) ContainsJson(obj any, opts ...JsonOption) *Assertion {
+	c.t.Helper()
+
+	opts = append([]JsonOption{JsonSubset()}, opts...)
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkEqJson(t, c.r, obj, opts)
+	})
+}
+
+// Compare the json value at the path
+func (c *Assertion) EqJsonPath(path string, value any, opts ...JsonOption) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkEqJsonPath(t, c.r, path, value, opts)
+	})
+}
+
+// Check the type of the json value at the path
+func (c *Assertion) JsonPathType(path string, typ JsonType) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkJsonPathType(t, c.r, path, typ)
+	})
+}
+
+// Check the length of the array, object or string at the path
+func (c *Assertion) JsonPathLen(path string, length int) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkJsonPathLen(t, c.r, path, length)
+	})
+}
+
+// Check the json value exists at the path
+func (c *Assertion) HasJsonPath(path string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkHasJsonPath(t, c.r, path)
+	})
+}
+
+// Check the json value does not exist at the path
+func (c *Assertion) NoJsonPath(path string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkNoJsonPath(t, c.r, path)
+	})
+}
+
+// Compare with the golden file `testdata/<name>.golden`
+func (c *Assertion) Snapshot(name string, opts ...SnapshotOption) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkSnapshot(t, c.r, name, opts)
+	})
+}
+
+// Check the first value of the header
+func (c *Assertion) EqHeader(key string, value string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkEqHeader(t, c.r, key, value)
+	})
+}
+
+// Check all values of the header in order
+func (c *Assertion) EqHeaderValues(key string, values ...string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkEqHeaderValues(t, c.r, key, values)
+	})
+}
+
+// Check the first value of the header contains substr
+func (c *Assertion) ContainsHeader(key string, substr string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkContainsHeader(t, c.r, key, substr)
+	})
+}
+
+// Check the first value of the header matches the regular expression
+func (c *Assertion) MatchHeader(key string, pattern string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkMatchHeader(t, c.r, key, pattern)
+	})
+}
+
+// Check the header is set
+func (c *Assertion) HasHeader(key string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkHasHeader(t, c.r, key)
+	})
+}
+
+// Check the header is not set
+func (c *Assertion) NoHeader(key string) *Assertion {
+	c.t.Helper()
+
+	return c.run(func(t testing.TB) {
+		t.Helper()
+		checkNoHeader(t, c.r, key)
+	})
+}
+
+// Returns failures collected so far.
+// It is always empty if the assertion is created by Require.
+func (c *Assertion) Failures() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string{}, c.failures...)
+}
+
+// Report the collected failures together.
+// It does not stop the test, and is called automatically at the end of the test.
+func (c *Assertion) Done() {
+	c.t.Helper()
+
+	c.mu.Lock()
+	failures := c.failures
+	c.failures = nil
+	c.mu.Unlock()
+
+	if len(failures) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("%d assertion(s) failed:\n%s", len(failures), strings.Join(failures, "\n"))
+	if settings := c.r.dumpSettings(); settings.Level != DumpOff {
+		message += "\n" + dumpExchange(c.r, settings)
+	}
+
+	c.t.Errorf("%s", message)
+}
+
+// Runs the check.
+// In soft mode, failures are collected and the check is stopped without stopping the test.
+func (c *Assertion) run(check func(t testing.TB)) *Assertion {
+	c.t.Helper()
+
+	if c.soft {
+		c.runSoft(check)
+	} else {
+		check(c.t)
+	}
+
+	return c
+}
+
+func (c *Assertion) runSoft(check func(t testing.TB)) {
+	c.t.Helper()
+
+	defer func() {
+		if r := recover(); r != nil && r != errSoftFailNow {
+			panic(r)
+		}
+	}()
+
+	check(&softT{TB: c.t, assertion: c})
+}
+
+func (c *Assertion) addFailure(message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = append(c.failures, message)
+}
+
+// testing.TB that collects failures into the assertion.
+// The exchange is dumped once by Done instead of each failure.
+type softT struct {
+	testing.TB
+
+	assertion *Assertion
+	failed    bool
+}
+
+func (c *softT) Errorf(format string, args ...any) {
+	c.failed = true
+	c.assertion.addFailure(fmt.Sprintf(format, args...))
+}
+
+func (c *softT) Error(args ...any) {
+	c.failed = true
+	c.assertion.addFailure(fmt.Sprint(args...))
+}
+
+func (c *softT) Fatalf(format string, args ...any) {
+	c.Errorf(format, args...)
+	c.FailNow()
+}
+
+func (c *softT) Fatal(args ...any) {
+	c.Error(args...)
+	c.FailNow()
+}
+
+func (c *softT) Fail() {
+	c.failed = true
+}
+
+func (c *softT) FailNow() {
+	c.failed = true
+	panic(errSoftFailNow)
+}
+
+func (c *softT) Failed() bool {
+	return c.failed || c.TB.Failed()
+}
@@ -0,0 +1,83 @@
+package easy_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssert(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		m, err := easy.NewMock("/", http.MethodGet, "")
+		require.NoError(t, err)
+
+		m.Handler(Handler)
+
+		a := m.Assert(t).
+			Ok().
+			EqBody("OK").
+			ContainsHeader("Content-Type", "text/plain").
+			NoHeader("Location")
+
+		require.Empty(t, a.Failures())
+		a.Done()
+	})
+
+	t.Run("collect all failures", func(t *testing.T) {
+		r := newJsonResponse(`{"name": "cateiru"}`)
+
+		var failures []string
+		msg := expectFail(t, func(t testing.TB) {
+			a := r.Assert(t).
+				Status(404).
+				EqHeader("Content-Type", "application/json").
+				EqJsonPath("$.name", "aaaa").
+				HasJsonPath("$.name")
+
+			failures = a.Failures()
+			a.Done()
+		})
+
+		require.Len(t, failures, 3)
+		require.Contains(t, msg, "3 assertion(s) failed")
+		require.Contains(t, msg, "unexpected status code")
+		require.Contains(t, msg, "header Content-Type is not set")
+		require.Contains(t, msg, `$.name: expected "aaaa", got "cateiru"`)
+	})
+
+	t.Run("report only once", func(t *testing.T) {
+		r := newJsonResponse(`{}`)
+
+		msg := expectFail(t, func(t testing.TB) {
+			a := r.Assert(t).Status(500)
+			a.Done()
+			a.Done()
+		})
+
+		require.Equal(t, 1, strings.Count(msg, "assertion(s) failed"))
+	})
+}
+
+func TestRequire(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		r := newJsonResponse(`{"name": "cateiru"}`)
+
+		r.Require(t).Ok().EqJson(map[string]any{"name": "cateiru"})
+	})
+
+	t.Run("stop at first failure", func(t *testing.T) {
+		r := newJsonResponse(`{"name": "cateiru"}`)
+
+		reached := false
+		msg := expectFail(t, func(t testing.TB) {
+			r.Require(t).Status(404)
+			reached = true
+		})
+
+		require.False(t, reached)
+		require.Contains(t, msg, "unexpected status code")
+	})
+}
@@ -0,0 +1,423 @@
+package easy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Recorded HTTP interactions.
+// It is saved as YAML if the file extension is `.yaml` or `.yml`, otherwise as JSON.
+// Bodies are stored as text, and bodies that are not valid UTF-8 are stored base64-encoded.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// A pair of the request and the response.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request" yaml:"request"`
+	Response CassetteResponse `json:"response" yaml:"response"`
+}
+
+type CassetteRequest struct {
+	Method string      `json:"method" yaml:"method"`
+	URL    string      `json:"url" yaml:"url"`
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	// Raw bytes of the body
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+	// `base64` in the file if Body is not valid UTF-8. It is empty after loading.
+	BodyEncoding string `json:"bodyEncoding,omitempty" yaml:"bodyEncoding,omitempty"`
+}
+
+type CassetteResponse struct {
+	Status int         `json:"status" yaml:"status"`
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	// Raw bytes of the body
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+	// `base64` in the file if Body is not valid UTF-8. It is empty after loading.
+	BodyEncoding string `json:"bodyEncoding,omitempty" yaml:"bodyEncoding,omitempty"`
+}
+
+const cassetteBase64 = "base64"
+
+// Load the cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{}
+	if isYamlFile(path) {
+		err = yaml.Unmarshal(b, cassette)
+	} else {
+		err = json.Unmarshal(b, cassette)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	for _, i := range cassette.Interactions {
+		i.Request.Body, err = decodeCassetteBody(i.Request.Body, i.Request.BodyEncoding)
+		if err == nil {
+			i.Response.Body, err = decodeCassetteBody(i.Response.Body, i.Response.BodyEncoding)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		i.Request.BodyEncoding = ""
+		i.Response.BodyEncoding = ""
+	}
+
+	return cassette, nil
+}
+
+// Save the cassette to the file.
+// Parent directories are created if they don't exist.
+func (c *Cassette) Save(path string) error {
+	encoded := &Cassette{Interactions: make([]*Interaction, 0, len(c.Interactions))}
+	for _, i := range c.Interactions {
+		i := *i
+		i.Request.Body, i.Request.BodyEncoding = encodeCassetteBody(i.Request.Body)
+		i.Response.Body, i.Response.BodyEncoding = encodeCassetteBody(i.Response.Body)
+		encoded.Interactions = append(encoded.Interactions, &i)
+	}
+
+	var b []byte
+	var err error
+	if isYamlFile(path) {
+		b, err = yaml.Marshal(encoded)
+	} else {
+		buf := &bytes.Buffer{}
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(encoded)
+		b = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// Encodes the body with base64 if it is not valid UTF-8.
+func encodeCassetteBody(body string) (string, string) {
+	if utf8.ValidString(body) {
+		return body, ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(body)), cassetteBase64
+}
+
+func decodeCassetteBody(body string, encoding string) (string, error) {
+	switch encoding {
+	case "":
+		return body, nil
+	case cassetteBase64:
+		b, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 body: %w", err)
+		}
+		return string(b), nil
+	}
+	return "", fmt.Errorf("unknown body encoding %s", encoding)
+}
+
+func isYamlFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Option of cassette.
+type CassetteOption func(*cassetteConfig)
+
+type cassetteConfig struct {
+	matchers      []CassetteMatcher
+	redactHeaders []string
+	redactQuery   []string
+	redactJson    [][]pathSegment
+	masks         []snapshotMask
+	transport     http.RoundTripper
+
+	err error
+}
+
+func newCassetteConfig(opts []CassetteOption, matchers []CassetteMatcher) *cassetteConfig {
+	config := &cassetteConfig{
+		matchers:      matchers,
+		redactHeaders: append([]string{}, RedactHeaders...),
+		transport:     http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// Criteria of matching requests with recorded requests.
+// The requests are redacted before they are matched.
+type CassetteMatcher func(r *CassetteRequest, recorded *CassetteRequest) bool
+
+// Match requests on the criteria.
+// Default is MatchMethod and MatchURL for the transport,
+// and MatchMethod, MatchPath and MatchQuery for the server.
+func CassetteMatchOn(matchers ...CassetteMatcher) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.matchers = matchers
+	}
+}
+
+// Replace values of the headers with `<redacted>` before saving.
+// Default is RedactHeaders.
+func CassetteRedactHeaders(keys ...string) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.redactHeaders = keys
+	}
+}
+
+// Replace values of the query params with `<redacted>` before saving.
+func CassetteRedactQuery(keys ...string) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.redactQuery = append(c.redactQuery, keys...)
+	}
+}
+
+// Replace json values at the paths in request and response bodies with `<redacted>` before saving.
+// Paths are JSONPath or JSON Pointer, and JSONPath can use wildcards.
+func CassetteRedactJson(paths ...string) CassetteOption {
+	return func(c *cassetteConfig) {
+		for _, path := range paths {
+			segments, err := parsePath(path)
+			if err != nil {
+				c.err = err
+				return
+			}
+			c.redactJson = append(c.redactJson, segments)
+		}
+	}
+}
+
+// Replace strings matched by the regular expression in URLs, headers and bodies before saving.
+func CassetteRedact(pattern string, replacement string) CassetteOption {
+	return func(c *cassetteConfig) {
+		reg, err := regexp.Compile(pattern)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.masks = append(c.masks, snapshotMask{pattern: reg, replacement: replacement})
+	}
+}
+
+// Transport that sends real requests while recording.
+// Default is http.DefaultTransport.
+func CassetteRealTransport(transport http.RoundTripper) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.transport = transport
+	}
+}
+
+// Match the method
+func MatchMethod() CassetteMatcher {
+	return func(r *CassetteRequest, recorded *CassetteRequest) bool {
+		return r.Method == recorded.Method
+	}
+}
+
+// Match the whole URL.
+// Query params are compared regardless of their order.
+// If either URL has no host, such as requests received by the server, the paths and queries are matched.
+func MatchURL() CassetteMatcher {
+	return func(r *CassetteRequest, recorded *CassetteRequest) bool {
+		u1, err1 := url.Parse(r.URL)
+		u2, err2 := url.Parse(recorded.URL)
+		if err1 != nil || err2 != nil {
+			return r.URL == recorded.URL
+		}
+
+		if u1.Host != "" && u2.Host != "" && (u1.Scheme != u2.Scheme || u1.Host != u2.Host) {
+			return false
+		}
+		return u1.Path == u2.Path && reflect.DeepEqual(u1.Query(), u2.Query())
+	}
+}
+
+// Match the path
+func MatchPath() CassetteMatcher {
+	return func(r *CassetteRequest, recorded *CassetteRequest) bool {
+		u1, err1 := url.Parse(r.URL)
+		u2, err2 := url.Parse(recorded.URL)
+		return err1 == nil && err2 == nil && u1.Path == u2.Path
+	}
+}
+
+// Match the query params, regardless of their order
+func MatchQuery() CassetteMatcher {
+	return func(r *CassetteRequest, recorded *CassetteRequest) bool {
+		u1, err1 := url.Parse(r.URL)
+		u2, err2 := url.Parse(recorded.URL)
+		return err1 == nil && err2 == nil && reflect.DeepEqual(u1.Query(), u2.Query())
+	}
+}
+
+// Match the body.
+// Json bodies are compared semantically.
+func MatchBody() CassetteMatcher {
+	return func(r *CassetteRequest, recorded *CassetteRequest) bool {
+		if r.Body == recorded.Body {
+			return true
+		}
+
+		expected, err := decodeJson([]byte(recorded.Body))
+		if err != nil {
+			return false
+		}
+		comparer, err := newJsonComparer(nil)
+		if err != nil {
+			return false
+		}
+		diffs, err := comparer.diff(expected, []byte(r.Body))
+		return err == nil && len(diffs) == 0
+	}
+}
+
+// Match values of the headers
+func MatchHeader(keys ...string) CassetteMatcher {
+	return func(r *CassetteRequest, recorded *CassetteRequest) bool {
+		for _, key := range keys {
+			if !reflect.DeepEqual(headerValues(r.Header, key), headerValues(recorded.Header, key)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func (c *cassetteConfig) match(r *CassetteRequest, recorded *CassetteRequest) bool {
+	for _, matcher := range c.matchers {
+		if !matcher(r, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+// Converts the request to the redacted form of the cassette.
+func (c *cassetteConfig) newRequest(r *http.Request, body []byte) *CassetteRequest {
+	req := &CassetteRequest{
+		Method: r.Method,
+		URL:    r.URL.String(),
+		Header: r.Header.Clone(),
+		Body:   string(body),
+	}
+	c.redactRequest(req)
+
+	return req
+}
+
+func (c *cassetteConfig) redactRequest(r *CassetteRequest) {
+	r.URL = c.mask(c.redactURL(r.URL))
+	r.Header = c.redactHeader(r.Header)
+	r.Body = c.mask(c.redactBody(r.Body))
+}
+
+func (c *cassetteConfig) redactResponse(r *CassetteResponse) {
+	r.Header = c.redactHeader(r.Header)
+	r.Body = c.mask(c.redactBody(r.Body))
+}
+
+func (c *cassetteConfig) redactURL(rawURL string) string {
+	if len(c.redactQuery) == 0 {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	// Rebuilds the query by hand to keep the order of params.
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			continue
+		}
+		if containsString(c.redactQuery, name) {
+			params[i] = key + "=" + url.QueryEscape("<redacted>")
+		}
+	}
+	u.RawQuery = strings.Join(params, "&")
+
+	return u.String()
+}
+
+func (c *cassetteConfig) redactHeader(header http.Header) http.Header {
+	redacted := http.Header{}
+	for key, values := range header {
+		redacted[key] = append([]string{}, values...)
+
+		for i := range values {
+			for _, r := range c.redactHeaders {
+				if http.CanonicalHeaderKey(r) == http.CanonicalHeaderKey(key) {
+					redacted[key][i] = "<redacted>"
+				}
+			}
+			redacted[key][i] = c.mask(redacted[key][i])
+		}
+	}
+
+	if len(redacted) == 0 {
+		return nil
+	}
+	return redacted
+}
+
+func (c *cassetteConfig) redactBody(body string) string {
+	if len(c.redactJson) == 0 {
+		return body
+	}
+
+	doc, err := decodeJson([]byte(body))
+	if err != nil {
+		return body
+	}
+
+	for _, segments := range c.redactJson {
+		for _, m := range selectJson(doc, segments) {
+			doc = replaceJson(doc, m.path, "<redacted>")
+		}
+	}
+
+	buf := &strings.Builder{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return body
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (c *cassetteConfig) mask(s string) string {
+	for _, m := range c.masks {
+		s = m.pattern.ReplaceAllString(s, m.replacement)
+	}
+	return s
+}
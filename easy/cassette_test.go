@@ -0,0 +1,285 @@
+package easy_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+// Upstream API that counts requests.
+func upstreamServer(t *testing.T) (*easy.MockServer, *int) {
+	count := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		count++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"name":"cateiru","token":"secret-token"}`))
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		count++
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("\x89PNG\r\n\x1a\n\xff\x00"))
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		count++
+
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	})
+
+	return easy.StartMockServer(t, mux), &count
+}
+
+func TestCassetteTransport(t *testing.T) {
+	for _, name := range []string{"cassette.yaml", "cassette.json"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			s, count := upstreamServer(t)
+			path := filepath.Join(t.TempDir(), "cassettes", name)
+			opts := []easy.CassetteOption{
+				easy.CassetteRedactQuery("api_key"),
+				easy.CassetteRedactJson("$.token"),
+			}
+
+			// record
+			transport, err := easy.NewCassetteTransport(path, opts...)
+			require.NoError(t, err)
+			require.True(t, transport.Recording())
+
+			client := &http.Client{Transport: transport}
+
+			r, err := http.NewRequest(http.MethodGet, s.URL("/users?api_key=secret-key&page=1"), nil)
+			require.NoError(t, err)
+			r.Header.Set("Authorization", "Bearer secret")
+
+			resp, err := client.Do(r)
+			require.NoError(t, err)
+			easy.NewResponse(resp).EqJson(t, map[string]any{"name": "cateiru", "token": "secret-token"})
+
+			resp, err = client.Post(s.URL("/echo"), "text/plain", strings.NewReader("hello"))
+			require.NoError(t, err)
+			easy.NewResponse(resp).EqBody(t, "hello")
+
+			require.NoError(t, transport.Save())
+			require.Equal(t, 2, *count)
+
+			// secrets are redacted
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.NotContains(t, string(b), "secret")
+			require.Contains(t, string(b), "<redacted>")
+
+			// replay
+			transport, err = easy.NewCassetteTransport(path, opts...)
+			require.NoError(t, err)
+			require.False(t, transport.Recording())
+
+			client = &http.Client{Transport: transport}
+
+			resp, err = client.Get(s.URL("/users?page=1&api_key=other-key"))
+			require.NoError(t, err)
+			r2 := easy.NewResponse(resp)
+			r2.Ok(t)
+			r2.EqHeader(t, "Content-Type", "application/json")
+			r2.EqJson(t, map[string]any{"name": "cateiru", "token": "<redacted>"})
+
+			resp, err = client.Post(s.URL("/echo"), "text/plain", strings.NewReader("hello"))
+			require.NoError(t, err)
+			easy.NewResponse(resp).EqBody(t, "hello")
+
+			require.Equal(t, 2, *count)
+
+			_, err = client.Get(s.URL("/other"))
+			require.ErrorContains(t, err, "no interaction matches GET")
+		})
+	}
+}
+
+func TestCassetteBinaryBody(t *testing.T) {
+	for _, name := range []string{"cassette.yaml", "cassette.json"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			s, count := upstreamServer(t)
+			path := filepath.Join(t.TempDir(), name)
+			opts := []easy.CassetteOption{easy.CassetteMatchOn(easy.MatchMethod(), easy.MatchPath(), easy.MatchBody())}
+			binary := "\x89PNG\r\n\x1a\n\xff\x00"
+
+			transport, err := easy.NewCassetteTransport(path, opts...)
+			require.NoError(t, err)
+			client := &http.Client{Transport: transport}
+
+			resp, err := client.Get(s.URL("/binary"))
+			require.NoError(t, err)
+			easy.NewResponse(resp).EqBody(t, binary)
+
+			resp, err = client.Post(s.URL("/echo"), "application/octet-stream", strings.NewReader(binary))
+			require.NoError(t, err)
+			easy.NewResponse(resp).EqBody(t, binary)
+
+			require.NoError(t, transport.Save())
+
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Contains(t, string(b), "bodyEncoding")
+
+			// replay
+			transport, err = easy.NewCassetteTransport(path, opts...)
+			require.NoError(t, err)
+			require.False(t, transport.Recording())
+			client = &http.Client{Transport: transport}
+
+			resp, err = client.Get(s.URL("/binary"))
+			require.NoError(t, err)
+			easy.NewResponse(resp).EqBody(t, binary)
+
+			resp, err = client.Post(s.URL("/echo"), "application/octet-stream", strings.NewReader(binary))
+			require.NoError(t, err)
+			easy.NewResponse(resp).EqBody(t, binary)
+
+			require.Equal(t, 2, *count)
+		})
+	}
+}
+
+func TestCassetteMatchOn(t *testing.T) {
+	s, count := upstreamServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	opts := []easy.CassetteOption{easy.CassetteMatchOn(easy.MatchMethod(), easy.MatchPath(), easy.MatchBody())}
+
+	client := &http.Client{}
+	transport, err := easy.NewCassetteTransport(path, opts...)
+	require.NoError(t, err)
+	client.Transport = transport
+
+	for _, body := range []string{`{"a": 1}`, `{"a": 2}`} {
+		resp, err := client.Post(s.URL("/echo"), "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		easy.NewResponse(resp).Ok(t)
+	}
+	require.NoError(t, transport.Save())
+
+	transport, err = easy.NewCassetteTransport(path, opts...)
+	require.NoError(t, err)
+	client.Transport = transport
+
+	// Json bodies are compared semantically
+	resp, err := client.Post(s.URL("/echo"), "application/json", strings.NewReader(`{ "a":2 }`))
+	require.NoError(t, err)
+	easy.NewResponse(resp).EqJson(t, map[string]any{"a": 2})
+
+	_, err = client.Post(s.URL("/echo"), "application/json", strings.NewReader(`{"a": 3}`))
+	require.Error(t, err)
+
+	require.Equal(t, 2, *count)
+}
+
+func TestUseCassette(t *testing.T) {
+	s, count := upstreamServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	t.Run("record", func(t *testing.T) {
+		client := easy.UseCassette(t, path)
+
+		resp, err := client.Get(s.URL("/users"))
+		require.NoError(t, err)
+		easy.NewResponse(resp).Ok(t)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		client := easy.UseCassette(t, path)
+
+		resp, err := client.Get(s.URL("/users"))
+		require.NoError(t, err)
+		easy.NewResponse(resp).EqJsonPath(t, "$.name", "cateiru")
+	})
+
+	require.Equal(t, 1, *count)
+
+	t.Run("not saved on failure", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "failed.json")
+
+		expectFail(t, func(t testing.TB) {
+			client := easy.UseCassette(t, path)
+
+			resp, err := client.Get(s.URL("/users"))
+			require.NoError(t, err)
+			easy.NewResponse(resp).Status(t, http.StatusCreated)
+		})
+
+		_, err := os.Stat(path)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		easy.UpdateSnapshots = true
+		defer func() { easy.UpdateSnapshots = false }()
+
+		transport, err := easy.NewCassetteTransport(path)
+		require.NoError(t, err)
+		require.True(t, transport.Recording())
+	})
+}
+
+func TestCassetteServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+	cassette := &easy.Cassette{
+		Interactions: []*easy.Interaction{
+			{
+				Request: easy.CassetteRequest{Method: http.MethodGet, URL: "https://api.example.com/users?page=1"},
+				Response: easy.CassetteResponse{
+					Status: http.StatusOK,
+					Header: http.Header{"Content-Type": {"application/json"}},
+					Body:   `{"name":"cateiru"}`,
+				},
+			},
+			{
+				Request:  easy.CassetteRequest{Method: http.MethodDelete, URL: "https://api.example.com/users/1"},
+				Response: easy.CassetteResponse{Status: http.StatusNoContent},
+			},
+		},
+	}
+	require.NoError(t, cassette.Save(path))
+
+	t.Run("replay", func(t *testing.T) {
+		s := easy.StartCassetteServer(t, path)
+
+		resp := s.GetOK(t, "/users?page=1")
+		resp.EqHeader(t, "Content-Type", "application/json")
+		resp.EqJson(t, map[string]any{"name": "cateiru"})
+
+		s.Do(t, "/users/1", http.MethodDelete, nil).Status(t, http.StatusNoContent)
+	})
+
+	t.Run("unmatched", func(t *testing.T) {
+		message := expectFail(t, func(t testing.TB) {
+			s := easy.StartCassetteServer(t, path)
+
+			s.Get(t, "/users?page=2").Status(t, http.StatusNotImplemented)
+		})
+		require.Contains(t, message, "no interaction of the cassette matches:\n\tGET /users?page=2")
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		loaded, err := easy.LoadCassette(path)
+		require.NoError(t, err)
+
+		client := easy.NewHandlerClient(loaded.Handler())
+
+		resp, err := client.Get("http://example.com/users?page=1")
+		require.NoError(t, err)
+		easy.NewResponse(resp).EqJsonPath(t, "$.name", "cateiru")
+	})
+}
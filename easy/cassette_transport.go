@@ -0,0 +1,306 @@
+package easy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RoundTripper that records real exchanges to the cassette file, and replays them offline.
+//
+// If the cassette file exists, requests are replayed from it without network.
+// Otherwise, or when UpdateSnapshots is true, real requests are sent and recorded,
+// and Save writes them to the file.
+type CassetteTransport struct {
+	path      string
+	config    *cassetteConfig
+	recording bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	player   *cassettePlayer
+}
+
+func NewCassetteTransport(path string, opts ...CassetteOption) (*CassetteTransport, error) {
+	config := newCassetteConfig(opts, []CassetteMatcher{MatchMethod(), MatchURL()})
+	if config.err != nil {
+		return nil, config.err
+	}
+
+	c := &CassetteTransport{
+		path:   path,
+		config: config,
+	}
+
+	if !shouldUpdate() {
+		cassette, err := LoadCassette(path)
+		if err == nil {
+			c.cassette = cassette
+			c.player = newCassettePlayer(cassette, config)
+			return c, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	c.recording = true
+	c.cassette = &Cassette{}
+	return c, nil
+}
+
+// Create a client that records and replays the cassette,
+// and saves the cassette when the test finishes.
+// The cassette is not saved if the test fails, not to record broken interactions.
+//
+// Example:
+//
+//	client := easy.UseCassette(t, "testdata/cassettes/users.yaml", easy.CassetteRedactQuery("api_key"))
+//	resp, err := client.Get("https://api.example.com/users")
+func UseCassette(t testing.TB, path string, opts ...CassetteOption) *http.Client {
+	t.Helper()
+
+	transport, err := NewCassetteTransport(path, opts...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			return
+		}
+		err := transport.Save()
+		require.NoError(t, err)
+	})
+
+	return &http.Client{
+		Transport: transport,
+		Jar:       newJar(),
+	}
+}
+
+// Whether real requests are sent and recorded.
+func (c *CassetteTransport) Recording() bool {
+	return c.recording
+}
+
+// Write the recorded interactions to the cassette file.
+// It does nothing when replaying.
+func (c *CassetteTransport) Save() error {
+	if !c.recording {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cassette.Save(c.path)
+}
+
+func (c *CassetteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.recording {
+		i, err := c.player.find(c.config.newRequest(r, body))
+		if err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", c.path, err)
+		}
+		return i.Response.httpResponse(r), nil
+	}
+
+	return c.record(r, body)
+}
+
+// Sends the real request, and records the exchange.
+func (c *CassetteTransport) record(r *http.Request, body []byte) (*http.Response, error) {
+	req := r.Clone(r.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	resp, err := c.config.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{
+		Request: *c.config.newRequest(r, body),
+		Response: CassetteResponse{
+			Status: resp.StatusCode,
+			Header: resp.Header.Clone(),
+			Body:   string(respBody),
+		},
+	}
+	c.config.redactResponse(&i.Response)
+
+	c.mu.Lock()
+	c.cassette.Interactions = append(c.cassette.Interactions, i)
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+// Returns the handler that replays the cassette.
+// Unmatched requests get 501 Not Implemented.
+func (c *Cassette) Handler(opts ...CassetteOption) http.Handler {
+	config := newCassetteConfig(opts, []CassetteMatcher{MatchMethod(), MatchPath(), MatchQuery()})
+
+	return newCassettePlayer(c, config)
+}
+
+// Start mock server that replays the cassette, for the code under test that needs a URL.
+// The server is closed when the test finishes, and unmatched requests fail the test.
+//
+// Example:
+//
+//	s := easy.StartCassetteServer(t, "testdata/cassettes/users.yaml")
+//	client := api.NewClient(s.URL(""))
+func StartCassetteServer(t testing.TB, path string, opts ...CassetteOption) *MockServer {
+	t.Helper()
+
+	cassette, err := LoadCassette(path)
+	require.NoError(t, err)
+
+	return startCassetteServer(t, cassette, opts)
+}
+
+func startCassetteServer(t testing.TB, cassette *Cassette, opts []CassetteOption) *MockServer {
+	t.Helper()
+
+	config := newCassetteConfig(opts, []CassetteMatcher{MatchMethod(), MatchPath(), MatchQuery()})
+	require.NoError(t, config.err)
+
+	player := newCassettePlayer(cassette, config)
+	s := StartMockServer(t, player)
+	t.Cleanup(func() {
+		player.verify(t)
+	})
+
+	return s
+}
+
+// Replays interactions of the cassette.
+type cassettePlayer struct {
+	cassette *Cassette
+	config   *cassetteConfig
+
+	mu        sync.Mutex
+	used      []bool
+	unmatched []string
+}
+
+func newCassettePlayer(cassette *Cassette, config *cassetteConfig) *cassettePlayer {
+	return &cassettePlayer{
+		cassette: cassette,
+		config:   config,
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// Returns the first unused interaction that matches the request.
+// If all matched interactions are used, the last one is used again.
+func (c *cassettePlayer) find(r *CassetteRequest) (*Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var last *Interaction
+	for i, interaction := range c.cassette.Interactions {
+		if !c.config.match(r, &interaction.Request) {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return interaction, nil
+		}
+		last = interaction
+	}
+	if last != nil {
+		return last, nil
+	}
+
+	c.unmatched = append(c.unmatched, fmt.Sprintf("%s %s", r.Method, r.URL))
+	return nil, fmt.Errorf("no interaction matches %s %s", r.Method, r.URL)
+}
+
+func (c *cassettePlayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.config.err != nil {
+		http.Error(w, c.config.err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	i, err := c.find(c.config.newRequest(r, body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	for key, values := range i.Response.Header {
+		w.Header()[key] = append([]string{}, values...)
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(i.Response.Status)
+	w.Write([]byte(i.Response.Body))
+}
+
+// Reports requests that matched no interaction.
+func (c *cassettePlayer) verify(t testing.TB) {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.unmatched) > 0 {
+		t.Errorf("no interaction of the cassette matches:\n\t%s", strings.Join(c.unmatched, "\n\t"))
+	}
+}
+
+func (c *CassetteResponse) httpResponse(r *http.Request) *http.Response {
+	header := c.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(c.Body)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Status, http.StatusText(c.Status)),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       r,
+	}
+}
+
+// Reads the request body, and closes it as RoundTripper does.
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return []byte{}, nil
+	}
+	defer r.Body.Close()
+
+	return io.ReadAll(r.Body)
+}
@@ -0,0 +1,91 @@
+package easy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How long to wait for requests and connections to finish when checking leaks.
+const leakWaitTimeout = time.Second
+
+// Tracks in-flight requests and open connections of the server.
+type connTracker struct {
+	mu       sync.Mutex
+	inFlight int
+	conns    map[net.Conn]http.ConnState
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{
+		conns: map[net.Conn]http.ConnState{},
+	}
+}
+
+// Wraps the handler to count in-flight requests.
+func (c *connTracker) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		c.inFlight++
+		c.mu.Unlock()
+
+		defer func() {
+			c.mu.Lock()
+			c.inFlight--
+			c.mu.Unlock()
+		}()
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Used as http.Server.ConnState.
+func (c *connTracker) connState(conn net.Conn, state http.ConnState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch state {
+	case http.StateNew:
+		c.conns[conn] = state
+	case http.StateClosed, http.StateHijacked:
+		delete(c.conns, conn)
+	default:
+		// h2c reports states of the hijacked connection, but never reports it is closed.
+		if _, ok := c.conns[conn]; ok {
+			c.conns[conn] = state
+		}
+	}
+}
+
+// Returns an error if requests are in flight or connections are open.
+func (c *connTracker) leaks() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inFlight == 0 && len(c.conns) == 0 {
+		return nil
+	}
+
+	states := map[http.ConnState]int{}
+	for _, state := range c.conns {
+		states[state]++
+	}
+
+	return fmt.Errorf("%d request(s) in flight, %d connection(s) open (active: %d, idle: %d, new: %d)",
+		c.inFlight, len(c.conns), states[http.StateActive], states[http.StateIdle], states[http.StateNew])
+}
+
+// Waits until no requests are in flight and no connections are open.
+func (c *connTracker) waitLeaks(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := c.leaks()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
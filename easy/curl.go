@@ -0,0 +1,212 @@
+package easy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Log the request as a curl command when an assertion of Response or MockHandler fails.
+// Values of RedactHeaders are replaced with `<redacted>`.
+// It is the default of DumpSettings.Curl.
+var CurlOnFailure = false
+
+// Renders the request as an equivalent curl command, to reproduce it by hand.
+// Headers, cookies and the body are included.
+// multipart/form-data bodies are rendered as form fields.
+//
+// Example:
+//
+//	r, err := http.NewRequest(http.MethodGet, "https://example.com/users", nil)
+//	fmt.Println(easy.Curl(r))
+//	// curl 'https://example.com/users'
+func Curl(r *http.Request) string {
+	return curlCommand(r, nil)
+}
+
+// Returns the request as a curl command.
+func (c *Response) Curl() string {
+	if c.Resp.Request == nil {
+		return ""
+	}
+	return Curl(c.Resp.Request)
+}
+
+// Returns the request as a curl command.
+func (c *MockHandler) Curl() string {
+	return Curl(c.R)
+}
+
+// Values of the headers in redact are replaced with `<redacted>`.
+func curlCommand(r *http.Request, redact []string) string {
+	body := requestBody(r)
+	if body == nil && r.RequestURI != "" && r.ContentLength == 0 {
+		// The length of received requests is known.
+		body = []byte{}
+	}
+	args := []string{"curl"}
+
+	switch {
+	case r.Method == http.MethodHead:
+		args = append(args, "--head")
+	case r.Method == "" || r.Method == http.MethodGet:
+		if len(body) > 0 {
+			args = append(args, "-X "+http.MethodGet)
+		}
+	case r.Method == http.MethodPost && len(body) > 0:
+		// curl sends POST with the body by default.
+	default:
+		args = append(args, "-X "+r.Method)
+	}
+
+	u := requestURL(r)
+	args = append(args, shellQuote(u.String()))
+
+	if r.Host != "" && r.Host != u.Host {
+		args = append(args, "-H "+shellQuote("Host: "+r.Host))
+	}
+
+	form, isForm := curlForm(r.Header.Get("Content-Type"), body)
+
+	keys := make([]string, 0, len(r.Header))
+	for key := range r.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch http.CanonicalHeaderKey(key) {
+		case "Cookie", "Content-Length":
+			continue
+		case "Content-Type":
+			// curl sets the content type with its own boundary.
+			if isForm {
+				continue
+			}
+		}
+		for _, value := range r.Header[key] {
+			if isRedacted(key, redact) {
+				value = "<redacted>"
+			}
+			args = append(args, "-H "+shellQuote(key+": "+value))
+		}
+	}
+
+	if cookies := r.Cookies(); len(cookies) > 0 {
+		pairs := make([]string, 0, len(cookies))
+		for _, cookie := range cookies {
+			value := cookie.Value
+			if isRedacted("Cookie", redact) {
+				value = "<redacted>"
+			}
+			pairs = append(pairs, cookie.Name+"="+value)
+		}
+		args = append(args, "-b "+shellQuote(strings.Join(pairs, "; ")))
+	}
+
+	switch {
+	case isForm:
+		args = append(args, form...)
+	case len(body) > 0:
+		args = append(args, "--data-raw "+shellQuote(string(body)))
+	}
+
+	command := strings.Join(args, " \\\n  ")
+	if body == nil {
+		command += " # body not available"
+	}
+	return command
+}
+
+// Returns the absolute URL of the request.
+// Requests received by the server have only the path.
+func requestURL(r *http.Request) *url.URL {
+	u := *r.URL
+	if u.Host == "" {
+		u.Host = r.Host
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if r.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	return &u
+}
+
+// Converts multipart/form-data body to `-F` options.
+func curlForm(contentType string, body []byte) ([]string, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" || body == nil {
+		return nil, false
+	}
+
+	args := []string{}
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+
+		if filename := part.FileName(); filename != "" {
+			field := fmt.Sprintf("%s=@%s", part.FormName(), filename)
+			if t := part.Header.Get("Content-Type"); t != "" {
+				field += ";type=" + t
+			}
+			args = append(args, "-F "+shellQuote(field))
+			continue
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil, false
+		}
+		// --form-string doesn't interpret `@` and `<` in the value.
+		args = append(args, "--form-string "+shellQuote(part.FormName()+"="+string(value)))
+	}
+
+	return args, true
+}
+
+// Quotes the string for POSIX shells.
+// Strings with control characters are quoted as `$'...'`.
+func shellQuote(s string) string {
+	if utf8.ValidString(s) && strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\t'
+	}) == -1 {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
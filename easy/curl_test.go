@@ -0,0 +1,167 @@
+package easy_test
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurl(t *testing.T) {
+	t.Run("GET", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodGet, "https://example.com/users?page=1&q=a%20b", nil)
+		require.NoError(t, err)
+		r.Header.Set("Accept", "application/json")
+		r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+		r.AddCookie(&http.Cookie{Name: "id", Value: "1"})
+
+		require.Equal(t, "curl \\\n"+
+			"  'https://example.com/users?page=1&q=a%20b' \\\n"+
+			"  -H 'Accept: application/json' \\\n"+
+			"  -b 'session=abc; id=1'", easy.Curl(r))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodPost, "http://example.com/users", strings.NewReader(`{"name":"it's me"}`))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/json")
+
+		require.Equal(t, "curl \\\n"+
+			"  'http://example.com/users' \\\n"+
+			"  -H 'Content-Type: application/json' \\\n"+
+			`  --data-raw '{"name":"it'\''s me"}'`, easy.Curl(r))
+	})
+
+	t.Run("urlencoded", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodPut, "http://example.com/users/1", strings.NewReader(url.Values{"name": {"a&b"}}.Encode()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		require.Equal(t, "curl \\\n"+
+			"  -X PUT \\\n"+
+			"  'http://example.com/users/1' \\\n"+
+			"  -H 'Content-Type: application/x-www-form-urlencoded' \\\n"+
+			"  --data-raw 'name=a%26b'", easy.Curl(r))
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		file, err := os.Open("test_image.png")
+		require.NoError(t, err)
+		defer file.Close()
+
+		m := easy.NewMultipart()
+		require.NoError(t, m.Insert("name", "@cateiru"))
+		require.NoError(t, m.InsertFile("image", file))
+
+		r, err := http.NewRequest(http.MethodPost, "http://example.com/upload", m.Export())
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", m.ContentType())
+
+		require.Equal(t, "curl \\\n"+
+			"  'http://example.com/upload' \\\n"+
+			"  --form-string 'name=@cateiru' \\\n"+
+			"  -F 'image=@test_image.png;type=image/png'", easy.Curl(r))
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodDelete, "http://example.com/", strings.NewReader("a\x00'\xff"))
+		require.NoError(t, err)
+
+		require.Equal(t, "curl \\\n"+
+			"  -X DELETE \\\n"+
+			"  'http://example.com/' \\\n"+
+			`  --data-raw $'a\x00\'\xff'`, easy.Curl(r))
+	})
+
+	t.Run("MockHandler", func(t *testing.T) {
+		m, err := easy.NewMock("/users", http.MethodHead, "")
+		require.NoError(t, err)
+		m.R.Header.Set("X-Token", "abc")
+
+		require.Equal(t, "curl \\\n"+
+			"  --head \\\n"+
+			"  'http://example.com/users' \\\n"+
+			"  -H 'X-Token: abc'", m.Curl())
+	})
+
+	t.Run("MockHandler JSON", func(t *testing.T) {
+		m, err := easy.NewJson("/users", http.MethodPost, JsonData{Nya: "aaaa"})
+		require.NoError(t, err)
+
+		// the body is read by the handler
+		m.Handler(func(w http.ResponseWriter, r *http.Request) {
+			io.ReadAll(r.Body)
+		})
+
+		require.Equal(t, "curl \\\n"+
+			"  'http://example.com/users' \\\n"+
+			"  -H 'Content-Type: application/json' \\\n"+
+			`  --data-raw '{"nya":"aaaa"}'`, m.Curl())
+	})
+
+	t.Run("MockHandler urlencoded", func(t *testing.T) {
+		m, err := easy.NewURLEncoded("/users", http.MethodPost, url.Values{"name": {"a&b"}})
+		require.NoError(t, err)
+
+		m.Handler(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+		})
+
+		require.Equal(t, "curl \\\n"+
+			"  'http://example.com/users' \\\n"+
+			"  -H 'Content-Type: application/x-www-form-urlencoded' \\\n"+
+			"  --data-raw 'name=a%26b'", m.Curl())
+	})
+
+	t.Run("MockHandler multipart", func(t *testing.T) {
+		file, err := os.Open("test_image.png")
+		require.NoError(t, err)
+		defer file.Close()
+
+		multipart := easy.NewMultipart()
+		require.NoError(t, multipart.Insert("name", "cateiru"))
+		require.NoError(t, multipart.InsertFile("image", file))
+
+		m, err := easy.NewFormData("/upload", http.MethodPost, multipart)
+		require.NoError(t, err)
+
+		m.Handler(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseMultipartForm(32 << 20)
+		})
+
+		require.Equal(t, "curl \\\n"+
+			"  'http://example.com/upload' \\\n"+
+			"  --form-string 'name=cateiru' \\\n"+
+			"  -F 'image=@test_image.png;type=image/png'", m.Curl())
+	})
+}
+
+func TestCurlOnFailure(t *testing.T) {
+	settings := easy.DefaultDumpSettings()
+	settings.Level = easy.DumpOff
+	settings.Curl = true
+	s := easy.StartMockServer(t, http.HandlerFunc(Handler), easy.Dump(settings))
+
+	resp := s.PostJson(t, "/users", JsonData{Nya: "aaaa"},
+		easy.WithHeader("Authorization", "Bearer secret-token"),
+		easy.WithCookie(&http.Cookie{Name: "session", Value: "secret-session"}),
+	)
+	require.Contains(t, resp.Curl(), "Bearer secret-token")
+
+	msg := expectFail(t, func(t testing.TB) {
+		resp.Status(t, http.StatusCreated)
+	})
+
+	require.Contains(t, msg, "--- curl ---")
+	require.Contains(t, msg, "'"+s.URL("/users")+"'")
+	require.Contains(t, msg, "-H 'Authorization: <redacted>'")
+	require.Contains(t, msg, "-b 'session=<redacted>'")
+	require.Contains(t, msg, `--data-raw '{"nya":"aaaa"}`)
+	require.NotContains(t, msg, "secret")
+	require.NotContains(t, msg, "--- request ---")
+}
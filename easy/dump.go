@@ -0,0 +1,256 @@
+package easy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Verbosity of the exchange dumped when an assertion fails.
+type DumpLevel int
+
+const (
+	// Don't dump.
+	DumpOff DumpLevel = iota
+	// Dump method, URL, status and headers.
+	DumpHeaders
+	// Dump headers and bodies.
+	DumpFull
+)
+
+// Defaults of DumpSettings.
+// They are shared by every test, so set them in TestMain,
+// and use the Dump option or MockHandler.SetDump to change them for parallel tests.
+var (
+	// Verbosity of the request and response dumped when an assertion of Response or MockHandler fails.
+	DumpOnFailure = DumpFull
+	// Bodies longer than this are truncated in the dump.
+	DumpBodyLimit = 4096
+	// Values of these headers are replaced with `<redacted>` in the dump.
+	RedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+)
+
+// Settings of the dump logged when an assertion fails.
+type DumpSettings struct {
+	Level DumpLevel
+	// Bodies longer than this are truncated. 0 is unlimited.
+	BodyLimit int
+	// Values of these headers are replaced with `<redacted>`.
+	RedactHeaders []string
+	// Log the request as a curl command too.
+	Curl bool
+}
+
+// Returns the settings of DumpOnFailure, DumpBodyLimit, RedactHeaders and CurlOnFailure.
+func DefaultDumpSettings() DumpSettings {
+	return DumpSettings{
+		Level:         DumpOnFailure,
+		BodyLimit:     DumpBodyLimit,
+		RedactHeaders: append([]string{}, RedactHeaders...),
+		Curl:          CurlOnFailure,
+	}
+}
+
+// Returns the settings, or the defaults if they are not set.
+func dumpSettingsOf(settings *DumpSettings) DumpSettings {
+	if settings == nil {
+		return DefaultDumpSettings()
+	}
+	return *settings
+}
+
+// Returns testing.TB that logs the dump of the exchange before the first failure is reported.
+func withDump(t testing.TB, r Result) testing.TB {
+	settings := r.dumpSettings()
+	if settings.Level == DumpOff && !settings.Curl {
+		return t
+	}
+	switch t.(type) {
+	case *dumpT, *softT:
+		return t
+	}
+
+	return &dumpT{TB: t, r: r, settings: settings}
+}
+
+type dumpT struct {
+	testing.TB
+
+	r        Result
+	settings DumpSettings
+	dumped   bool
+}
+
+func (c *dumpT) Errorf(format string, args ...any) {
+	c.TB.Helper()
+
+	c.dump()
+	c.TB.Errorf(format, args...)
+}
+
+func (c *dumpT) Error(args ...any) {
+	c.TB.Helper()
+
+	c.dump()
+	c.TB.Error(args...)
+}
+
+func (c *dumpT) Fatalf(format string, args ...any) {
+	c.TB.Helper()
+
+	c.dump()
+	c.TB.Fatalf(format, args...)
+}
+
+func (c *dumpT) Fatal(args ...any) {
+	c.TB.Helper()
+
+	c.dump()
+	c.TB.Fatal(args...)
+}
+
+func (c *dumpT) dump() {
+	c.TB.Helper()
+
+	if c.dumped {
+		return
+	}
+	c.dumped = true
+
+	if c.settings.Level != DumpOff {
+		c.TB.Logf("%s", dumpExchange(c.r, c.settings))
+	}
+	if req := c.r.request(); c.settings.Curl && req != nil {
+		c.TB.Logf("--- curl ---\n%s", curlCommand(req, c.settings.RedactHeaders))
+	}
+}
+
+// Formats the request and the response.
+func dumpExchange(r Result, settings DumpSettings) string {
+	b := &strings.Builder{}
+
+	b.WriteString("--- request ---\n")
+	if req := r.request(); req != nil {
+		fmt.Fprintf(b, "%s %s\n", req.Method, req.URL)
+		writeDumpHeader(b, req.Header, settings.RedactHeaders)
+
+		if settings.Level >= DumpFull {
+			b.WriteString("\n")
+			b.WriteString(dumpBody(requestBody(req), settings.BodyLimit))
+		}
+	} else {
+		b.WriteString("(unknown)\n")
+	}
+
+	b.WriteString("--- response ---\n")
+	fmt.Fprintf(b, "%d %s\n", r.statusCode(), http.StatusText(r.statusCode()))
+	writeDumpHeader(b, r.header(), settings.RedactHeaders)
+
+	if settings.Level >= DumpFull {
+		b.WriteString("\n")
+		b.WriteString(dumpBody(r.bodyBytes(), settings.BodyLimit))
+	}
+
+	return b.String()
+}
+
+func writeDumpHeader(b *strings.Builder, header http.Header, redact []string) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+			if isRedacted(key, redact) {
+				value = "<redacted>"
+			}
+			fmt.Fprintf(b, "%s: %s\n", key, value)
+		}
+	}
+}
+
+func isRedacted(key string, redact []string) bool {
+	canonical := textproto.CanonicalMIMEHeaderKey(key)
+	for _, redacted := range redact {
+		if textproto.CanonicalMIMEHeaderKey(redacted) == canonical {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns pretty-printed json, or the body as is.
+// Long body is truncated.
+func dumpBody(body []byte, limit int) string {
+	if body == nil {
+		return "(body not available)\n"
+	}
+	if len(body) == 0 {
+		return "(empty)\n"
+	}
+
+	s := string(body)
+	if doc, err := decodeJson(body); err == nil {
+		if pretty, err := encodePrettyJson(doc); err == nil {
+			s = pretty
+		}
+	}
+
+	if limit > 0 && len(s) > limit {
+		// Don't cut a multi-byte character in half.
+		end := limit
+		for end > 0 && !utf8.RuneStart(s[end]) {
+			end--
+		}
+		s = fmt.Sprintf("%s\n... (%d bytes truncated)", s[:end], len(s)-end)
+	}
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+
+	return s
+}
+
+// Returns the request body without consuming it.
+// Returns nil if it cannot be read again.
+func requestBody(r *http.Request) []byte {
+	if r.GetBody == nil {
+		if r.Body == nil || r.Body == http.NoBody {
+			return []byte{}
+		}
+		return nil
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func encodePrettyJson(doc any) (string, error) {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
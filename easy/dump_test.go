@@ -0,0 +1,178 @@
+package easy_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOnFailure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		w.Write([]byte(`{"name":"cateiru"}`))
+	})
+
+	s := easy.NewMockServer(mux)
+	defer s.Close()
+
+	t.Run("Response", func(t *testing.T) {
+		resp := s.PostJson(t, "/users", JsonData{Nya: "aaaa"}, easy.WithHeader("Authorization", "Bearer secret-token"))
+
+		msg := expectFail(t, func(t testing.TB) {
+			resp.Status(t, http.StatusCreated)
+		})
+
+		require.Contains(t, msg, "--- request ---")
+		require.Contains(t, msg, "POST "+s.URL("/users"))
+		require.Contains(t, msg, "Authorization: <redacted>")
+		require.Contains(t, msg, "\"nya\": \"aaaa\"")
+		require.Contains(t, msg, "--- response ---")
+		require.Contains(t, msg, "200 OK")
+		require.Contains(t, msg, "Set-Cookie: <redacted>")
+		require.Contains(t, msg, "\"name\": \"cateiru\"")
+		require.NotContains(t, msg, "secret-token")
+		require.NotContains(t, msg, "secret-session")
+	})
+
+	t.Run("MockHandler", func(t *testing.T) {
+		m, err := easy.NewMock("/users", http.MethodGet, "")
+		require.NoError(t, err)
+
+		m.Handler(Handler)
+
+		msg := expectFail(t, func(t testing.TB) {
+			m.EqBody(t, "NG")
+		})
+
+		require.Contains(t, msg, "GET /users")
+		require.Contains(t, msg, "200 OK")
+		require.Contains(t, msg, "OK")
+	})
+
+	t.Run("MockHandler request body", func(t *testing.T) {
+		m, err := easy.NewJson("/users", http.MethodPost, JsonData{Nya: "aaaa"})
+		require.NoError(t, err)
+
+		m.Handler(func(w http.ResponseWriter, r *http.Request) {
+			io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		msg := expectFail(t, func(t testing.TB) {
+			m.Ok(t)
+		})
+
+		require.Contains(t, msg, "POST /users")
+		require.Contains(t, msg, "\"nya\": \"aaaa\"")
+		require.Contains(t, msg, "(empty)")
+		require.NotContains(t, msg, "(body not available)")
+	})
+
+	t.Run("DumpHeaders", func(t *testing.T) {
+		settings := easy.DefaultDumpSettings()
+		settings.Level = easy.DumpHeaders
+		s := easy.StartMockServer(t, mux, easy.Dump(settings))
+
+		resp := s.Get(t, "/")
+
+		msg := expectFail(t, func(t testing.TB) {
+			resp.EqHeader(t, "Content-Type", "text/plain")
+		})
+
+		require.Contains(t, msg, "Content-Type: application/json")
+		require.NotContains(t, msg, "cateiru")
+	})
+
+	t.Run("DumpOff", func(t *testing.T) {
+		s := easy.StartMockServer(t, mux, easy.Dump(easy.DumpSettings{Level: easy.DumpOff}))
+
+		resp := s.Get(t, "/")
+
+		msg := expectFail(t, func(t testing.TB) {
+			resp.Ok(t)
+			resp.Status(t, 500)
+		})
+
+		require.NotContains(t, msg, "--- request ---")
+
+		msg = expectFail(t, func(t testing.TB) {
+			resp.Assert(t).Status(500).Done()
+		})
+
+		require.NotContains(t, msg, "--- request ---")
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		m, err := easy.NewMock("/", http.MethodGet, "")
+		require.NoError(t, err)
+
+		settings := easy.DefaultDumpSettings()
+		settings.BodyLimit = 10
+		m.SetDump(settings)
+
+		m.Handler(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strings.Repeat("a", 100)))
+		})
+
+		msg := expectFail(t, func(t testing.TB) {
+			m.Status(t, 500)
+		})
+
+		require.Contains(t, msg, "(90 bytes truncated)")
+	})
+
+	t.Run("truncate multi-byte characters", func(t *testing.T) {
+		m, err := easy.NewMock("/", http.MethodGet, "")
+		require.NoError(t, err)
+
+		settings := easy.DefaultDumpSettings()
+		settings.BodyLimit = 5
+		m.SetDump(settings)
+
+		m.Handler(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ねこねこ"))
+		})
+
+		msg := expectFail(t, func(t testing.TB) {
+			m.Status(t, 500)
+		})
+
+		require.True(t, utf8.ValidString(msg))
+		require.Contains(t, msg, "ね\n... (9 bytes truncated)")
+	})
+
+	t.Run("soft assertion dumps once", func(t *testing.T) {
+		resp := s.Get(t, "/")
+
+		msg := expectFail(t, func(t testing.TB) {
+			resp.Assert(t).Status(500).EqHeader("Content-Type", "text/plain").Done()
+		})
+
+		require.Equal(t, 1, strings.Count(msg, "--- request ---"))
+	})
+
+	t.Run("redact headers", func(t *testing.T) {
+		settings := easy.DefaultDumpSettings()
+		settings.RedactHeaders = append(settings.RedactHeaders, "X-Api-Key")
+		s := easy.StartMockServer(t, mux, easy.Dump(settings))
+
+		resp := s.Get(t, "/", easy.WithHeader("X-Api-Key", "secret-key"))
+
+		msg := expectFail(t, func(t testing.TB) {
+			resp.Status(t, 500)
+		})
+
+		require.Contains(t, msg, "X-Api-Key: <redacted>")
+		require.NotContains(t, msg, "secret-key")
+
+		// the defaults are not changed
+		require.NotContains(t, easy.RedactHeaders, "X-Api-Key")
+	})
+}
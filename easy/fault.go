@@ -0,0 +1,253 @@
+package easy
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Seed of random faults when FaultSeed is not set.
+const defaultFaultSeed = 1
+
+// Injects a fault into the handler.
+// The random source is seeded by FaultSeed, and shared by all faults of the server.
+type faultWrapper func(handler http.Handler, rnd *faultRand) http.Handler
+
+// Random source that is safe for concurrent use.
+type faultRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newFaultRand(seed int64) *faultRand {
+	return &faultRand{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (c *faultRand) int63n(n int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rnd.Int63n(n)
+}
+
+func (c *faultRand) float64() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rnd.Float64()
+}
+
+// Seed of random faults such as RandomLatency and RandomFailures.
+// With the same seed, the same sequence of requests gets the same faults.
+func FaultSeed(seed int64) ServerOption {
+	return func(c *serverConfig) {
+		c.faultSeed = seed
+	}
+}
+
+// Delay every response.
+// The delay is canceled when the client cancels the request.
+func Latency(d time.Duration) ServerOption {
+	return addFault(func(handler http.Handler, rnd *faultRand) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !sleepContext(r, d) {
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	})
+}
+
+// Delay every response by a random duration in [min, max].
+func RandomLatency(min time.Duration, max time.Duration) ServerOption {
+	return addFault(func(handler http.Handler, rnd *faultRand) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := min
+			if max > min {
+				d += time.Duration(rnd.int63n(int64(max-min) + 1))
+			}
+
+			if !sleepContext(r, d) {
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	})
+}
+
+// Reply with the status to the first n requests without calling the handler.
+//
+// Example:
+//
+//	// The first 2 requests fail, and the third succeeds.
+//	s := easy.StartMockServer(t, mux, easy.FailFirst(2, http.StatusServiceUnavailable))
+func FailFirst(n int, status int) ServerOption {
+	return addFault(func(handler http.Handler, rnd *faultRand) http.Handler {
+		mu := sync.Mutex{}
+		count := 0
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			count++
+			fail := count <= n
+			mu.Unlock()
+
+			if fail {
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	})
+}
+
+// Reply with the status to requests at the rate (0 to 1) without calling the handler.
+func RandomFailures(rate float64, status int) ServerOption {
+	return addFault(func(handler http.Handler, rnd *faultRand) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rnd.float64() < rate {
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	})
+}
+
+// Send the headers and the first n bytes of the body, then close the connection abruptly.
+// Negative n is 0.
+// Content-Length is the length of the whole body, so the client fails to read the body.
+func CloseMidResponse(n int) ServerOption {
+	if n < 0 {
+		n = 0
+	}
+
+	return addFault(func(handler http.Handler, rnd *faultRand) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := recordResponse(handler, r)
+			body := rec.Body.Bytes()
+			if n < len(body) {
+				body = body[:n]
+			}
+
+			writeRecordedHeader(w, rec, rec.Body.Len())
+			w.Write(body)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+
+			// Closes the connection, or resets the stream of HTTP/2.
+			panic(http.ErrAbortHandler)
+		})
+	})
+}
+
+// Cut the body to the first n bytes.
+// Negative n is 0.
+// Content-Length is the length of the cut body, so the client reads it without errors.
+func TruncateBody(n int) ServerOption {
+	if n < 0 {
+		n = 0
+	}
+
+	return addFault(func(handler http.Handler, rnd *faultRand) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := recordResponse(handler, r)
+			body := rec.Body.Bytes()
+			if n < len(body) {
+				body = body[:n]
+			}
+
+			writeRecordedHeader(w, rec, len(body))
+			w.Write(body)
+		})
+	})
+}
+
+// Send the body in chunks of the size, waiting the interval between chunks.
+// It panics if the size is less than 1.
+func SlowDrip(size int, interval time.Duration) ServerOption {
+	if size < 1 {
+		panic("easy: SlowDrip size must be at least 1, got " + strconv.Itoa(size))
+	}
+
+	return addFault(func(handler http.Handler, rnd *faultRand) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := recordResponse(handler, r)
+			body := rec.Body.Bytes()
+
+			writeRecordedHeader(w, rec, len(body))
+			for i := 0; i < len(body); i += size {
+				if i > 0 && !sleepContext(r, interval) {
+					return
+				}
+
+				end := i + size
+				if end > len(body) {
+					end = len(body)
+				}
+				w.Write(body[i:end])
+				if f, ok := w.(http.Flusher); ok {
+					f.Flush()
+				}
+			}
+		})
+	})
+}
+
+func addFault(fault faultWrapper) ServerOption {
+	return func(c *serverConfig) {
+		c.faults = append(c.faults, fault)
+	}
+}
+
+// Wraps the handler with the faults.
+// The first fault is applied first.
+func applyFaults(handler http.Handler, faults []faultWrapper, seed int64) http.Handler {
+	if len(faults) == 0 {
+		return handler
+	}
+
+	rnd := newFaultRand(seed)
+	for i := len(faults) - 1; i >= 0; i-- {
+		handler = faults[i](handler, rnd)
+	}
+	return handler
+}
+
+// Calls the handler, and returns its response without sending it.
+func recordResponse(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+
+	return rec
+}
+
+func writeRecordedHeader(w http.ResponseWriter, rec *httptest.ResponseRecorder, contentLength int) {
+	for key, values := range rec.Header() {
+		w.Header()[key] = append([]string{}, values...)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(contentLength))
+	w.WriteHeader(rec.Code)
+}
+
+// Waits for the duration.
+// Returns false if the request is canceled.
+func sleepContext(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
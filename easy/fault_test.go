@@ -0,0 +1,162 @@
+package easy_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func helloHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("hello world"))
+}
+
+func TestLatency(t *testing.T) {
+	t.Run("fixed", func(t *testing.T) {
+		s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.Latency(50*time.Millisecond))
+
+		start := time.Now()
+		s.GetOK(t, "/").EqBody(t, "hello world")
+		require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.RandomLatency(10*time.Millisecond, 30*time.Millisecond))
+
+		start := time.Now()
+		s.GetOK(t, "/").EqBody(t, "hello world")
+		require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	})
+
+	t.Run("client timeout", func(t *testing.T) {
+		s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.Latency(time.Minute))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL("/"), nil)
+		require.NoError(t, err)
+
+		resp, err := s.Client.Do(r)
+		if err == nil {
+			resp.Body.Close()
+		}
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestFailFirst(t *testing.T) {
+	s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.FailFirst(2, http.StatusServiceUnavailable))
+
+	s.Get(t, "/").Status(t, http.StatusServiceUnavailable)
+	s.Get(t, "/").Status(t, http.StatusServiceUnavailable)
+	s.GetOK(t, "/").EqBody(t, "hello world")
+	s.GetOK(t, "/").EqBody(t, "hello world")
+}
+
+func TestRandomFailures(t *testing.T) {
+	statuses := func(opts ...easy.ServerOption) []int {
+		s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), opts...)
+
+		result := []int{}
+		for i := 0; i < 20; i++ {
+			result = append(result, s.Get(t, "/").Resp.StatusCode)
+		}
+		return result
+	}
+
+	a := statuses(easy.RandomFailures(0.5, http.StatusInternalServerError), easy.FaultSeed(10))
+	b := statuses(easy.RandomFailures(0.5, http.StatusInternalServerError), easy.FaultSeed(10))
+	c := statuses(easy.RandomFailures(0.5, http.StatusInternalServerError), easy.FaultSeed(20))
+
+	require.Equal(t, a, b)
+	require.NotEqual(t, a, c)
+	require.Contains(t, a, http.StatusOK)
+	require.Contains(t, a, http.StatusInternalServerError)
+
+	require.NotContains(t, statuses(easy.RandomFailures(0, http.StatusInternalServerError)), http.StatusInternalServerError)
+	require.NotContains(t, statuses(easy.RandomFailures(1, http.StatusInternalServerError)), http.StatusOK)
+}
+
+func TestCloseMidResponse(t *testing.T) {
+	for _, opts := range [][]easy.ServerOption{{}, {easy.HTTP2()}} {
+		opts := append(opts, easy.CloseMidResponse(5))
+
+		s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), opts...)
+
+		resp, err := s.Client.Get(s.URL("/"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.Error(t, err)
+		require.Equal(t, "hello", string(body))
+	}
+
+	t.Run("negative", func(t *testing.T) {
+		s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.CloseMidResponse(-1))
+
+		resp, err := s.Client.Get(s.URL("/"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.Error(t, err)
+		require.Empty(t, body)
+	})
+}
+
+func TestTruncateBody(t *testing.T) {
+	s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.TruncateBody(5))
+
+	resp := s.GetOK(t, "/")
+	resp.EqBody(t, "hello")
+	resp.EqHeader(t, "Content-Length", "5")
+
+	t.Run("negative", func(t *testing.T) {
+		s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.TruncateBody(-1))
+
+		resp := s.GetOK(t, "/")
+		resp.EqBody(t, "")
+		resp.EqHeader(t, "Content-Length", "0")
+	})
+}
+
+func TestSlowDrip(t *testing.T) {
+	s := easy.StartMockServer(t, http.HandlerFunc(helloHandler), easy.SlowDrip(4, 20*time.Millisecond))
+
+	start := time.Now()
+	resp := s.GetOK(t, "/")
+	resp.EqBody(t, "hello world")
+
+	// 3 chunks, 2 intervals
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	t.Run("invalid size", func(t *testing.T) {
+		require.Panics(t, func() { easy.SlowDrip(0, time.Millisecond) })
+		require.Panics(t, func() { easy.SlowDrip(-1, time.Millisecond) })
+	})
+}
+
+func TestFaultsAndRecorder(t *testing.T) {
+	rec := easy.NewRecorder()
+	s := easy.StartMockServer(t, http.HandlerFunc(helloHandler),
+		easy.Record(rec),
+		easy.FailFirst(1, http.StatusBadGateway),
+		easy.TruncateBody(5),
+	)
+
+	s.Get(t, "/").Status(t, http.StatusBadGateway)
+	s.GetOK(t, "/").EqBody(t, "hello")
+
+	// Failed requests are recorded too.
+	rec.Match(http.MethodGet, "/").Received(t, 2)
+}
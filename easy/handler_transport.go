@@ -0,0 +1,97 @@
+package easy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+// RoundTripper that sends requests to the handler in-process, without opening sockets.
+// Any URL can be used, and the handler receives the request as the server does.
+//
+// Example:
+//
+//	client := easy.NewHandlerClient(mux)
+//	resp, err := client.Get("http://example.com/users")
+//	easy.NewResponse(resp).Ok(t)
+type HandlerTransport struct {
+	Handler http.Handler
+	// RemoteAddr of requests received by the handler.
+	// Default is `192.0.2.1:1234`, the same as httptest.NewRequest.
+	RemoteAddr string
+}
+
+func NewHandlerTransport(handler http.Handler) *HandlerTransport {
+	return &HandlerTransport{
+		Handler:    handler,
+		RemoteAddr: "192.0.2.1:1234",
+	}
+}
+
+// Create a client that sends requests to the handler.
+// It has its own cookie jar, and follows redirects.
+func NewHandlerClient(handler http.Handler) *http.Client {
+	return &http.Client{
+		Transport: NewHandlerTransport(handler),
+		Jar:       newJar(),
+	}
+}
+
+func (c *HandlerTransport) RoundTrip(r *http.Request) (resp *http.Response, err error) {
+	if r.Body != nil {
+		defer r.Body.Close()
+	}
+
+	req := c.serverRequest(r)
+	rec := httptest.NewRecorder()
+
+	defer func() {
+		if p := recover(); p != nil {
+			resp = nil
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+
+	c.Handler.ServeHTTP(rec, req)
+
+	resp = rec.Result()
+	resp.Request = r
+	return resp, nil
+}
+
+// Converts the client request to the request received by the handler.
+func (c *HandlerTransport) serverRequest(r *http.Request) *http.Request {
+	req := r.Clone(r.Context())
+
+	req.RequestURI = r.URL.RequestURI()
+	req.RemoteAddr = c.RemoteAddr
+	req.Proto = "HTTP/1.1"
+	req.ProtoMajor = 1
+	req.ProtoMinor = 1
+	req.Close = false
+
+	if req.Host == "" {
+		req.Host = r.URL.Host
+	}
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+	if req.ContentLength == 0 && req.Body != http.NoBody {
+		req.ContentLength = -1
+	}
+	// The handler receives the path and the query as the server does.
+	if u, err := url.ParseRequestURI(req.RequestURI); err == nil {
+		req.URL = u
+	}
+	if r.URL.Scheme == "https" {
+		req.TLS = &tls.ConnectionState{
+			Version:           tls.VersionTLS12,
+			HandshakeComplete: true,
+			ServerName:        req.Host,
+		}
+	}
+
+	return req
+}
@@ -0,0 +1,104 @@
+package easy_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", Handler)
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Host", r.Host)
+		w.Header().Set("X-Remote-Addr", r.RemoteAddr)
+		w.Header().Set("X-Request-URI", r.RequestURI)
+		if r.TLS != nil {
+			w.Header().Set("X-TLS", "true")
+		}
+		w.Write(body)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "login", Path: "/"})
+		http.Redirect(w, r, "/me", http.StatusFound)
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(c.Value))
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	})
+
+	client := easy.NewHandlerClient(mux)
+
+	t.Run("GET", func(t *testing.T) {
+		resp, err := client.Get("http://example.com/")
+		require.NoError(t, err)
+
+		r := easy.NewResponse(resp)
+		r.Ok(t)
+		r.EqBody(t, "OK")
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		resp, err := client.Post("https://example.com/echo?a=b", "text/plain", strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		r := easy.NewResponse(resp)
+		r.Ok(t)
+		r.EqBody(t, "hello")
+		r.EqHeader(t, "X-Method", http.MethodPost)
+		r.EqHeader(t, "X-Host", "example.com")
+		r.EqHeader(t, "X-Remote-Addr", "192.0.2.1:1234")
+		r.EqHeader(t, "X-Request-URI", "/echo?a=b")
+		r.EqHeader(t, "X-TLS", "true")
+		require.Equal(t, "https://example.com/echo?a=b", resp.Request.URL.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		resp, err := client.Post("http://example.com/echo", "application/json", strings.NewReader(`{"nya": "aaaa"}`))
+		require.NoError(t, err)
+
+		easy.NewResponse(resp).EqJson(t, JsonData{Nya: "aaaa"})
+	})
+
+	t.Run("redirect and cookie", func(t *testing.T) {
+		resp, err := client.Get("http://example.com/login")
+		require.NoError(t, err)
+
+		r := easy.NewResponse(resp)
+		r.Ok(t)
+		r.EqBody(t, "login")
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		_, err := client.Get("http://example.com/panic")
+		require.ErrorContains(t, err, "handler panicked: oops")
+	})
+}
+
+func TestHandlerTransport(t *testing.T) {
+	transport := easy.NewHandlerTransport(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.RemoteAddr))
+	}))
+	transport.RemoteAddr = "203.0.113.1:80"
+
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get("http://example.com/")
+	require.NoError(t, err)
+
+	easy.NewResponse(resp).EqBody(t, "203.0.113.1:80")
+}
@@ -0,0 +1,325 @@
+package easy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+// HTTP Archive (HAR) 1.2.
+// It can be opened by browser devtools.
+//
+// http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string `json:"startedDateTime"`
+	// Total time of the request in milliseconds
+	Time     float64     `json:"time"`
+	Request  HARRequest  `json:"request"`
+	Response HARResponse `json:"response"`
+	Cache    struct{}    `json:"cache"`
+	Timings  HARTimings  `json:"timings"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	// `base64` if Text is encoded in base64
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Load the HAR file.
+func LoadHAR(path string) (*HAR, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	har := &HAR{}
+	if err := json.Unmarshal(b, har); err != nil {
+		return nil, fmt.Errorf("failed to parse har %s: %w", path, err)
+	}
+
+	return har, nil
+}
+
+// Save the HAR to the file.
+// Parent directories are created if they don't exist.
+func (c *HAR) Save(path string) error {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Converts entries to a cassette, to replay the responses.
+func (c *HAR) Cassette() (*Cassette, error) {
+	cassette := &Cassette{}
+
+	for _, e := range c.Log.Entries {
+		body := e.Response.Content.Text
+		if e.Response.Content.Encoding == "base64" {
+			b, err := base64.StdEncoding.DecodeString(body)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode content of %s %s: %w", e.Request.Method, e.Request.URL, err)
+			}
+			body = string(b)
+		}
+
+		reqBody := ""
+		if e.Request.PostData != nil {
+			reqBody = e.Request.PostData.Text
+		}
+
+		cassette.Interactions = append(cassette.Interactions, &Interaction{
+			Request: CassetteRequest{
+				Method: e.Request.Method,
+				URL:    e.Request.URL,
+				Header: harHeader(e.Request.Headers),
+				Body:   reqBody,
+			},
+			Response: CassetteResponse{
+				Status: e.Response.Status,
+				Header: harResponseHeader(e.Response.Headers),
+				Body:   body,
+			},
+		})
+	}
+
+	return cassette, nil
+}
+
+// Start mock server that replies with the responses of the HAR file.
+// Requests are matched on the method, the path and the query by default, as StartCassetteServer.
+//
+// Example:
+//
+//	s := easy.StartHARServer(t, "testdata/captured.har")
+//	client := api.NewClient(s.URL(""))
+func StartHARServer(t testing.TB, path string, opts ...CassetteOption) *MockServer {
+	t.Helper()
+
+	har, err := LoadHAR(path)
+	require.NoError(t, err)
+
+	cassette, err := har.Cassette()
+	require.NoError(t, err)
+
+	return startCassetteServer(t, cassette, opts)
+}
+
+// Returns everything the handler received and sent as HAR.
+func (c *Recorder) HAR() *HAR {
+	har := &HAR{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "go-http-easy-test", Version: "2"},
+			Entries: []HAREntry{},
+		},
+	}
+
+	for _, r := range c.Requests() {
+		har.Log.Entries = append(har.Log.Entries, newHAREntry(r))
+	}
+
+	return har
+}
+
+func newHAREntry(r *RecordedRequest) HAREntry {
+	req := &http.Request{Header: r.Header}
+
+	e := HAREntry{
+		StartedDateTime: r.Time.Format(time.RFC3339Nano),
+		Request: HARRequest{
+			Method:      r.Method,
+			URL:         r.URL.String(),
+			HTTPVersion: r.Proto,
+			Cookies:     []HARNameValue{},
+			Headers:     harNameValues(r.Header),
+			QueryString: []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(r.Body),
+		},
+		Response: HARResponse{
+			Cookies:     []HARNameValue{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+	}
+
+	for _, cookie := range req.Cookies() {
+		e.Request.Cookies = append(e.Request.Cookies, HARNameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+	query := r.URL.Query()
+	for _, key := range sortedHeaderKeys(http.Header(query)) {
+		for _, value := range query[key] {
+			e.Request.QueryString = append(e.Request.QueryString, HARNameValue{Name: key, Value: value})
+		}
+	}
+	if len(r.Body) > 0 {
+		e.Request.PostData = &HARPostData{
+			MimeType: r.Header.Get("Content-Type"),
+			Text:     string(r.Body),
+		}
+	}
+
+	if r.Response == nil {
+		return e
+	}
+
+	resp := &http.Response{Header: r.Response.Header}
+	e.Response.Status = r.Response.Status
+	e.Response.StatusText = http.StatusText(r.Response.Status)
+	e.Response.HTTPVersion = r.Proto
+	e.Response.Headers = harNameValues(r.Response.Header)
+	e.Response.RedirectURL = r.Response.Header.Get("Location")
+	e.Response.BodySize = len(r.Response.Body)
+	for _, cookie := range resp.Cookies() {
+		e.Response.Cookies = append(e.Response.Cookies, HARNameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	e.Response.Content = HARContent{
+		Size:     len(r.Response.Body),
+		MimeType: r.Response.Header.Get("Content-Type"),
+	}
+	if isTextContent(e.Response.Content.MimeType, r.Response.Body) {
+		e.Response.Content.Text = string(r.Response.Body)
+	} else {
+		e.Response.Content.Text = base64.StdEncoding.EncodeToString(r.Response.Body)
+		e.Response.Content.Encoding = "base64"
+	}
+
+	elapsed := float64(r.Response.Time.Sub(r.Time)) / float64(time.Millisecond)
+	e.Time = elapsed
+	e.Timings.Wait = elapsed
+
+	return e
+}
+
+func isTextContent(mimeType string, body []byte) bool {
+	if !utf8.Valid(body) {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		// Unknown type, but the body is valid UTF-8.
+		return true
+	}
+	return !strings.HasPrefix(mediaType, "image/") &&
+		!strings.HasPrefix(mediaType, "audio/") &&
+		!strings.HasPrefix(mediaType, "video/") &&
+		mediaType != "application/octet-stream"
+}
+
+func harNameValues(header http.Header) []HARNameValue {
+	values := []HARNameValue{}
+	for _, key := range sortedHeaderKeys(header) {
+		for _, value := range header[key] {
+			values = append(values, HARNameValue{Name: key, Value: value})
+		}
+	}
+	return values
+}
+
+func harHeader(values []HARNameValue) http.Header {
+	header := http.Header{}
+	for _, v := range values {
+		// HTTP/2 pseudo headers such as `:authority`
+		if strings.HasPrefix(v.Name, ":") {
+			continue
+		}
+		header.Add(v.Name, v.Value)
+	}
+	if len(header) == 0 {
+		return nil
+	}
+	return header
+}
+
+// Headers of the response to replay.
+// Content-Encoding and Content-Length are dropped, since browsers save decoded content.
+func harResponseHeader(values []HARNameValue) http.Header {
+	header := harHeader(values)
+	if header == nil {
+		return nil
+	}
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return header
+}
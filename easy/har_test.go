@@ -0,0 +1,102 @@
+package easy_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderHAR(t *testing.T) {
+	image, err := os.ReadFile("test_image.png")
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"cateiru"}`))
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(image)
+	})
+
+	rec := easy.NewRecorder()
+	s := easy.StartMockServer(t, mux, easy.Record(rec))
+
+	s.PostJson(t, "/users?page=1", JsonData{Nya: "aaaa"}, easy.WithCookie(&http.Cookie{Name: "id", Value: "1"})).Ok(t)
+	s.GetOK(t, "/image")
+
+	path := filepath.Join(t.TempDir(), "out.har")
+	require.NoError(t, rec.HAR().Save(path))
+
+	har, err := easy.LoadHAR(path)
+	require.NoError(t, err)
+
+	require.Equal(t, "1.2", har.Log.Version)
+	require.Len(t, har.Log.Entries, 2)
+
+	e := har.Log.Entries[0]
+	require.Equal(t, http.MethodPost, e.Request.Method)
+	require.Equal(t, s.URL("/users?page=1"), e.Request.URL)
+	require.Equal(t, "HTTP/1.1", e.Request.HTTPVersion)
+	require.Equal(t, []easy.HARNameValue{{Name: "page", Value: "1"}}, e.Request.QueryString)
+	require.Equal(t, []easy.HARNameValue{{Name: "id", Value: "1"}}, e.Request.Cookies)
+	require.Equal(t, "application/json", e.Request.PostData.MimeType)
+	require.JSONEq(t, `{"nya": "aaaa"}`, e.Request.PostData.Text)
+
+	require.Equal(t, http.StatusOK, e.Response.Status)
+	require.Equal(t, "OK", e.Response.StatusText)
+	require.Equal(t, []easy.HARNameValue{{Name: "session", Value: "abc"}}, e.Response.Cookies)
+	require.Equal(t, "application/json", e.Response.Content.MimeType)
+	require.Equal(t, `{"name":"cateiru"}`, e.Response.Content.Text)
+	require.Empty(t, e.Response.Content.Encoding)
+	require.GreaterOrEqual(t, e.Time, 0.0)
+
+	e = har.Log.Entries[1]
+	require.Nil(t, e.Request.PostData)
+	require.Equal(t, "base64", e.Response.Content.Encoding)
+	require.Equal(t, len(image), e.Response.Content.Size)
+
+	// replay the exported HAR
+	replay := easy.StartHARServer(t, path)
+
+	resp := replay.PostJson(t, "/users?page=1", JsonData{Nya: "aaaa"})
+	resp.Ok(t)
+	resp.EqJson(t, map[string]any{"name": "cateiru"})
+
+	resp = replay.GetOK(t, "/image")
+	resp.EqHeader(t, "Content-Type", "image/png")
+	require.Equal(t, image, resp.Body().Bytes())
+}
+
+func TestHARServer(t *testing.T) {
+	s := easy.StartHARServer(t, "testdata/har/browser.har")
+
+	resp := s.GetOK(t, "/users?page=1")
+	resp.EqHeader(t, "Content-Type", "application/json")
+	resp.NoHeader(t, "Content-Encoding")
+	resp.EqJsonPath(t, "$[*].name", []string{"cateiru", "yuto"})
+
+	resp = s.PostJson(t, "/users", map[string]any{"name": "nya"})
+	resp.Status(t, http.StatusCreated)
+	resp.EqHeader(t, "Location", "/users/3")
+	resp.EqBody(t, "ok")
+}
+
+func TestHARCassette(t *testing.T) {
+	har, err := easy.LoadHAR("testdata/har/browser.har")
+	require.NoError(t, err)
+
+	cassette, err := har.Cassette()
+	require.NoError(t, err)
+
+	require.Len(t, cassette.Interactions, 2)
+	require.Equal(t, http.Header{"Accept": {"application/json"}}, cassette.Interactions[0].Request.Header)
+	require.Equal(t, `{"name":"nya"}`, cassette.Interactions[1].Request.Body)
+	require.Equal(t, "ok", cassette.Interactions[1].Response.Body)
+}
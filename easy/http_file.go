@@ -0,0 +1,638 @@
+package easy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Requests written in `.http` file of VS Code REST Client and JetBrains HTTP Client.
+//
+// Requests are separated by `###`, and variables are defined as `@name = value` and used as `{{name}}`.
+// Responses of requests named by `# @name login` can be referred as
+// `{{login.response.body.$.token}}` and `{{login.response.headers.Location}}`.
+// `{{$processEnv NAME}}` and `{{$timestamp}}` are also available.
+//
+// Assertions are written as `?? <subject> [key] <operator> [value]`:
+//
+//	?? status == 201
+//	?? header Content-Type contains json
+//	?? body contains cateiru
+//	?? json $.name == "cateiru"
+//
+// Subjects are `status`, `header`, `body` and `json`,
+// and operators are `==`, `!=`, `contains`, `matches` and `exists`.
+type HTTPFile struct {
+	Variables map[string]string
+	Requests  []*HTTPRequest
+}
+
+type HTTPRequest struct {
+	// Name of `# @name`
+	Name string
+	// Title after `###`
+	Title  string
+	Method string
+	// URL with the variables as is
+	URL        string
+	Header     http.Header
+	Body       string
+	Assertions []*HTTPAssertion
+	// Line number of the request line
+	Line int
+}
+
+type HTTPAssertion struct {
+	Subject  string
+	Key      string
+	Operator string
+	Value    string
+	Line     int
+}
+
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+var (
+	httpVariableDefinition = regexp.MustCompile(`^@([\w.-]+)\s*=\s*(.*)$`)
+	httpRequestName        = regexp.MustCompile(`^(?:#|//)\s*@name\s+(\S+)`)
+	httpVariableReference  = regexp.MustCompile(`{{\s*([^{}]+?)\s*}}`)
+)
+
+// Load the `.http` file.
+// Bodies of `< ./file.json` are read from the path relative to the file.
+func LoadHTTPFile(path string) (*HTTPFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file, err := parseHTTPFile(f, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Parse requests of `.http` format.
+// Bodies of `< ./file.json` are read from the path relative to the working directory.
+func ParseHTTPFile(r io.Reader) (*HTTPFile, error) {
+	return parseHTTPFile(r, ".")
+}
+
+func parseHTTPFile(r io.Reader, dir string) (*HTTPFile, error) {
+	file := &HTTPFile{Variables: map[string]string{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	lines := []string{}
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	start := 0
+	title := ""
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !strings.HasPrefix(lines[i], "###") {
+			continue
+		}
+
+		req, err := parseHTTPBlock(file, lines[start:i], start+1, dir)
+		if err != nil {
+			return nil, err
+		}
+		if req != nil {
+			req.Title = title
+			file.Requests = append(file.Requests, req)
+		}
+
+		if i < len(lines) {
+			title = strings.TrimSpace(strings.TrimPrefix(lines[i], "###"))
+		}
+		start = i + 1
+	}
+
+	return file, nil
+}
+
+// Parses lines between `###`.
+// Returns nil if there is no request line.
+func parseHTTPBlock(file *HTTPFile, lines []string, firstLine int, dir string) (*HTTPRequest, error) {
+	req := &HTTPRequest{Header: http.Header{}}
+
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		if m := httpRequestName.FindStringSubmatch(line); m != nil {
+			req.Name = m[1]
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if m := httpVariableDefinition.FindStringSubmatch(line); m != nil {
+			file.Variables[m[1]] = strings.TrimSpace(m[2])
+			continue
+		}
+		break
+	}
+	if i == len(lines) {
+		return nil, nil
+	}
+
+	// request line
+	req.Line = firstLine + i
+	req.Method, req.URL = parseRequestLine(strings.TrimSpace(lines[i]))
+	i++
+
+	// multi-line query params
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "??") {
+			// assertion
+			break
+		}
+		if !strings.HasPrefix(line, "?") && !strings.HasPrefix(line, "&") {
+			break
+		}
+		req.URL += line
+	}
+
+	// headers
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			i++
+			break
+		}
+		if strings.HasPrefix(line, "??") {
+			a, err := parseHTTPAssertion(line, firstLine+i)
+			if err != nil {
+				return nil, err
+			}
+			req.Assertions = append(req.Assertions, a)
+			continue
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid header: %s", firstLine+i, line)
+		}
+		req.Header.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	// body
+	body := []string{}
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "??") {
+			a, err := parseHTTPAssertion(strings.TrimSpace(lines[i]), firstLine+i)
+			if err != nil {
+				return nil, err
+			}
+			req.Assertions = append(req.Assertions, a)
+			continue
+		}
+		body = append(body, lines[i])
+	}
+	for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+		body = body[:len(body)-1]
+	}
+
+	if len(body) == 1 && strings.HasPrefix(body[0], "< ") {
+		path := strings.TrimSpace(strings.TrimPrefix(body[0], "< "))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = string(b)
+	} else {
+		req.Body = strings.Join(body, "\n")
+	}
+
+	return req, nil
+}
+
+// Parses `METHOD URL HTTP/1.1`.
+// The method can be omitted, and it is GET.
+func parseRequestLine(line string) (string, string) {
+	method := http.MethodGet
+	if m, rest, ok := strings.Cut(line, " "); ok && containsString(httpMethods, strings.ToUpper(m)) {
+		method = strings.ToUpper(m)
+		line = strings.TrimSpace(rest)
+	}
+
+	if i := strings.LastIndex(line, " HTTP/"); i != -1 {
+		line = strings.TrimSpace(line[:i])
+	}
+
+	return method, line
+}
+
+// Parses `?? <subject> [key] <operator> [value]`.
+func parseHTTPAssertion(line string, lineNumber int) (*HTTPAssertion, error) {
+	fields := strings.Fields(strings.TrimSpace(strings.TrimPrefix(line, "??")))
+	a := &HTTPAssertion{Line: lineNumber}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("line %d: empty assertion", lineNumber)
+	}
+	a.Subject = fields[0]
+	fields = fields[1:]
+
+	switch a.Subject {
+	case "status", "body":
+	case "header", "json":
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: %s needs a key: %s", lineNumber, a.Subject, line)
+		}
+		a.Key = fields[0]
+		fields = fields[1:]
+	default:
+		return nil, fmt.Errorf("line %d: unknown subject %s: %s", lineNumber, a.Subject, line)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("line %d: operator is missing: %s", lineNumber, line)
+	}
+	a.Operator = fields[0]
+
+	switch a.Operator {
+	case "exists":
+		if a.Subject != "header" && a.Subject != "json" {
+			return nil, fmt.Errorf("line %d: exists is only for header and json: %s", lineNumber, line)
+		}
+		return a, nil
+	case "==", "!=", "contains", "matches":
+	default:
+		return nil, fmt.Errorf("line %d: unknown operator %s: %s", lineNumber, a.Operator, line)
+	}
+
+	// The value is the rest of the line, to keep spaces in it.
+	rest := strings.TrimSpace(strings.TrimPrefix(line, "??"))
+	for _, field := range []string{a.Subject, a.Key, a.Operator} {
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, field))
+	}
+	a.Value = rest
+
+	if a.Operator == "matches" {
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	return a, nil
+}
+
+// Option of running `.http` file.
+type HTTPFileOption func(*httpFileConfig)
+
+type httpFileConfig struct {
+	variables map[string]string
+}
+
+// Set the variable.
+// It overwrites the variable of the same name defined in the file.
+func HTTPVariable(name string, value string) HTTPFileOption {
+	return func(c *httpFileConfig) {
+		c.variables[name] = value
+	}
+}
+
+// Run requests of the `.http` file against the handler, as subtests.
+// The scheme and host of URLs are ignored, and cookies are kept between requests.
+//
+// Example:
+//
+//	easy.RunHTTPFile(t, handler, "testdata/users.http")
+func RunHTTPFile(t *testing.T, handler http.Handler, path string, opts ...HTTPFileOption) {
+	t.Helper()
+
+	client := NewHandlerClient(handler)
+	base := &url.URL{Scheme: "http", Host: "example.com"}
+
+	runHTTPFile(t, path, base, func(t testing.TB, r *http.Request) *Response {
+		t.Helper()
+
+		resp, err := client.Do(r)
+		require.NoError(t, err)
+		return NewResponse(resp)
+	}, opts)
+}
+
+// Run requests of the `.http` file against the server, as subtests.
+// The scheme and host of URLs are replaced with the server,
+// and default headers of the server are sent.
+//
+// Example:
+//
+//	s := easy.StartMockServer(t, handler)
+//	s.RunHTTPFile(t, "testdata/users.http")
+func (c *MockServer) RunHTTPFile(t *testing.T, path string, opts ...HTTPFileOption) {
+	t.Helper()
+
+	base, err := url.Parse(c.Server.URL)
+	require.NoError(t, err)
+
+	runHTTPFile(t, path, base, func(t testing.TB, r *http.Request) *Response {
+		t.Helper()
+
+		header := r.Header
+		r.Header = http.Header{}
+		c.insertHeaders(r)
+		for key, values := range header {
+			r.Header[key] = values
+		}
+
+		return c.send(t, r)
+	}, opts)
+}
+
+func runHTTPFile(t *testing.T, path string, base *url.URL, send func(testing.TB, *http.Request) *Response, opts []HTTPFileOption) {
+	t.Helper()
+
+	file, err := LoadHTTPFile(path)
+	require.NoError(t, err)
+
+	config := &httpFileConfig{variables: map[string]string{}}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	runner := &httpFileRunner{
+		variables: map[string]string{},
+		responses: map[string]*Response{},
+	}
+	for name, value := range file.Variables {
+		runner.variables[name] = value
+	}
+	for name, value := range config.variables {
+		runner.variables[name] = value
+	}
+
+	for _, req := range file.Requests {
+		req := req
+
+		t.Run(req.testName(), func(t *testing.T) {
+			r, err := runner.newRequest(req, base)
+			require.NoError(t, err, "%s:%d", path, req.Line)
+
+			resp := send(t, r)
+			resp.bodyBytes()
+			if req.Name != "" {
+				runner.responses[req.Name] = resp
+			}
+
+			for _, a := range req.Assertions {
+				location := fmt.Sprintf("%s:%d", path, a.Line)
+
+				value, err := runner.replace(a.Value)
+				require.NoError(t, err, location)
+				assertion := *a
+				assertion.Value = value
+
+				assertion.Check(&locatedT{TB: t, location: location}, resp)
+			}
+		})
+	}
+}
+
+func (c *HTTPRequest) testName() string {
+	switch {
+	case c.Name != "":
+		return c.Name
+	case c.Title != "":
+		return c.Title
+	default:
+		return c.Method + " " + c.URL
+	}
+}
+
+type httpFileRunner struct {
+	variables map[string]string
+	// Responses of named requests
+	responses map[string]*Response
+}
+
+// Creates the request to send, replacing the variables.
+func (c *httpFileRunner) newRequest(req *HTTPRequest, base *url.URL) (*http.Request, error) {
+	rawURL, err := c.replace(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = base.Scheme
+	u.Host = base.Host
+
+	body, err := c.replace(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequest(req.Method, u.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range req.Header {
+		for _, value := range values {
+			value, err := c.replace(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Header.Add(key, value)
+		}
+	}
+	if host := r.Header.Get("Host"); host != "" {
+		r.Host = host
+		r.Header.Del("Host")
+	}
+
+	return r, nil
+}
+
+// Replaces `{{name}}` in the string.
+func (c *httpFileRunner) replace(s string) (string, error) {
+	return c.replaceDepth(s, 0)
+}
+
+func (c *httpFileRunner) replaceDepth(s string, depth int) (string, error) {
+	if depth > 10 {
+		return "", fmt.Errorf("variables are nested too deeply: %s", s)
+	}
+
+	var err error
+	replaced := httpVariableReference.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		name := httpVariableReference.FindStringSubmatch(ref)[1]
+
+		var value string
+		value, err = c.resolve(name, depth)
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return replaced, nil
+}
+
+func (c *httpFileRunner) resolve(name string, depth int) (string, error) {
+	switch {
+	case strings.HasPrefix(name, "$processEnv "):
+		return os.Getenv(strings.TrimSpace(strings.TrimPrefix(name, "$processEnv "))), nil
+	case name == "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	}
+
+	if value, ok := c.variables[name]; ok {
+		return c.replaceDepth(value, depth+1)
+	}
+
+	// {{login.response.body.$.token}}
+	request, rest, ok := strings.Cut(name, ".response.")
+	if !ok {
+		return "", fmt.Errorf("variable %s is not defined", name)
+	}
+	resp, ok := c.responses[request]
+	if !ok {
+		return "", fmt.Errorf("response of %s is not available", request)
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "headers."):
+		return resp.Resp.Header.Get(strings.TrimPrefix(rest, "headers.")), nil
+	case rest == "body" || rest == "body.*":
+		return string(resp.bodyBytes()), nil
+	case strings.HasPrefix(rest, "body."):
+		return selectJsonString(resp.bodyBytes(), strings.TrimPrefix(rest, "body."))
+	}
+	return "", fmt.Errorf("unknown variable %s", name)
+}
+
+// Returns the json value at the path.
+// Strings are returned as is, and others are encoded as json.
+func selectJsonString(body []byte, path string) (string, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return "", err
+	}
+	doc, err := decodeJson(body)
+	if err != nil {
+		return "", err
+	}
+
+	matches := selectJson(doc, segments)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("json path %s is not found", path)
+	}
+	if s, ok := matches[0].value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(matches[0].value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Check the response satisfies the assertion.
+// Variables in the value are not replaced.
+func (c *HTTPAssertion) Check(t testing.TB, r *Response) {
+	t.Helper()
+	t = withDump(t, r)
+
+	var actual string
+	switch c.Subject {
+	case "status":
+		if c.Operator == "==" {
+			status, err := strconv.Atoi(c.Value)
+			require.NoError(t, err)
+			checkStatus(t, r, status)
+			return
+		}
+		actual = strconv.Itoa(r.statusCode())
+	case "header":
+		if c.Operator == "exists" {
+			checkHasHeader(t, r, c.Key)
+			return
+		}
+		actual = strings.Join(headerValues(r.header(), c.Key), ", ")
+	case "body":
+		actual = string(r.bodyBytes())
+	case "json":
+		switch c.Operator {
+		case "exists":
+			checkHasJsonPath(t, r, c.Key)
+			return
+		case "==", "!=":
+			// The value is compared as json, e.g. `"cateiru"` is a string.
+			var expected any = c.Value
+			if v, err := decodeJson([]byte(c.Value)); err == nil {
+				expected = v
+			}
+			if c.Operator == "==" {
+				checkEqJsonPath(t, r, c.Key, expected, nil)
+			} else {
+				diffs := diffJsonPath(t, r, c.Key, expected, nil)
+				require.NotEmpty(t, diffs, "json %s should not be %s", c.Key, c.Value)
+			}
+			return
+		}
+		var err error
+		actual, err = selectJsonString(r.bodyBytes(), c.Key)
+		require.NoError(t, err)
+	}
+
+	switch c.Operator {
+	case "==":
+		require.Equal(t, c.Value, actual, "%s %s", c.Subject, c.Key)
+	case "!=":
+		require.NotEqual(t, c.Value, actual, "%s %s", c.Subject, c.Key)
+	case "contains":
+		require.Contains(t, actual, c.Value, "%s %s", c.Subject, c.Key)
+	case "matches":
+		require.Regexp(t, c.Value, actual, "%s %s", c.Subject, c.Key)
+	}
+}
+
+// testing.TB that reports the location of the assertion with the failure.
+type locatedT struct {
+	testing.TB
+
+	location string
+}
+
+func (c *locatedT) Errorf(format string, args ...any) {
+	c.TB.Helper()
+
+	c.TB.Errorf("%s\n"+format, append([]any{c.location}, args...)...)
+}
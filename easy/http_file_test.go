@@ -0,0 +1,180 @@
+package easy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+// Users API for `.http` files.
+func usersHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		user := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user["id"] = 1
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/users/1")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(user)
+	})
+	mux.HandleFunc("/users/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":    1,
+			"name":  "cateiru",
+			"query": r.URL.Query().Encode(),
+		})
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: r.FormValue("name"), Path: "/"})
+		w.Write([]byte("welcome " + r.FormValue("name")))
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("user")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(c.Value))
+	})
+	return mux
+}
+
+func TestLoadHTTPFile(t *testing.T) {
+	file, err := easy.LoadHTTPFile("testdata/http/users.http")
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"host":        "http://localhost:8080",
+		"contentType": "application/json",
+		"name":        "cateiru",
+	}, file.Variables)
+	require.Len(t, file.Requests, 4)
+
+	r := file.Requests[0]
+	require.Equal(t, "create", r.Name)
+	require.Equal(t, "Create user", r.Title)
+	require.Equal(t, http.MethodPost, r.Method)
+	require.Equal(t, "{{host}}/users", r.URL)
+	require.Equal(t, http.Header{"Content-Type": {"{{contentType}}"}}, r.Header)
+	require.Equal(t, "{\n  \"name\": \"{{name}}\"\n}", r.Body)
+	require.Equal(t, 7, r.Line)
+	require.Len(t, r.Assertions, 5)
+	require.Equal(t, &easy.HTTPAssertion{Subject: "json", Key: "$.name", Operator: "==", Value: `"cateiru"`, Line: 17}, r.Assertions[3])
+
+	r = file.Requests[1]
+	require.Equal(t, http.MethodGet, r.Method)
+	require.Equal(t, "{{host}}{{create.response.headers.Location}}?verbose=true&lang=ja", r.URL)
+	require.Empty(t, r.Body)
+
+	// body from the file
+	require.Equal(t, "name=cateiru&password=secret\n", file.Requests[2].Body)
+
+	require.Empty(t, file.Requests[3].Title)
+}
+
+func TestParseHTTPFile(t *testing.T) {
+	t.Run("method is omitted", func(t *testing.T) {
+		file, err := easy.ParseHTTPFile(strings.NewReader("https://example.com/"))
+		require.NoError(t, err)
+
+		require.Len(t, file.Requests, 1)
+		require.Equal(t, http.MethodGet, file.Requests[0].Method)
+		require.Equal(t, "https://example.com/", file.Requests[0].URL)
+	})
+
+	t.Run("assertion after the request line", func(t *testing.T) {
+		file, err := easy.ParseHTTPFile(strings.NewReader("GET /users/1\n?page=1\n?? status == 200"))
+		require.NoError(t, err)
+
+		require.Len(t, file.Requests, 1)
+		require.Equal(t, "/users/1?page=1", file.Requests[0].URL)
+		require.Equal(t, []*easy.HTTPAssertion{{Subject: "status", Operator: "==", Value: "200", Line: 3}}, file.Requests[0].Assertions)
+
+		file, err = easy.ParseHTTPFile(strings.NewReader("GET /users/1\n?? status == 200"))
+		require.NoError(t, err)
+
+		require.Equal(t, "/users/1", file.Requests[0].URL)
+		require.Len(t, file.Requests[0].Assertions, 1)
+	})
+
+	t.Run("invalid assertion", func(t *testing.T) {
+		_, err := easy.ParseHTTPFile(strings.NewReader("GET /\n\n?? cookie a == b"))
+		require.ErrorContains(t, err, "line 3: unknown subject cookie")
+
+		_, err = easy.ParseHTTPFile(strings.NewReader("GET /\n\n?? status >= 200"))
+		require.ErrorContains(t, err, "line 3: unknown operator >=")
+	})
+}
+
+func TestRunHTTPFile(t *testing.T) {
+	t.Run("handler", func(t *testing.T) {
+		easy.RunHTTPFile(t, usersHandler(), "testdata/http/users.http")
+	})
+
+	t.Run("MockServer", func(t *testing.T) {
+		s := easy.StartMockServer(t, usersHandler())
+		s.RunHTTPFile(t, "testdata/http/users.http", easy.HTTPVariable("name", "cateiru"))
+	})
+
+}
+
+func TestHTTPAssertion(t *testing.T) {
+	s := easy.StartMockServer(t, usersHandler())
+	resp := s.GetOK(t, "/users/1")
+
+	file, err := easy.LoadHTTPFile("testdata/http/fail.http")
+	require.NoError(t, err)
+	require.Len(t, file.Requests, 1)
+
+	message := expectFail(t, func(t testing.TB) {
+		file.Requests[0].Assertions[0].Check(t, resp)
+	})
+	require.Contains(t, message, "unexpected status code")
+
+	for _, line := range []string{
+		"?? status != 404",
+		"?? header Content-Type == application/json",
+		"?? body contains cateiru",
+		"?? json $.name matches ^cate",
+		"?? json $.id != 2",
+		"?? json $.name != \"yuto\"",
+		"?? json $.query exists",
+	} {
+		file, err := easy.ParseHTTPFile(strings.NewReader("GET /\n\n" + line))
+		require.NoError(t, err)
+
+		file.Requests[0].Assertions[0].Check(t, resp)
+	}
+
+	message = expectFail(t, func(t testing.TB) {
+		file, err := easy.ParseHTTPFile(strings.NewReader("GET /\n\n?? json $.name == \"yuto\""))
+		require.NoError(t, err)
+
+		file.Requests[0].Assertions[0].Check(t, resp)
+	})
+	require.Contains(t, message, "$.name")
+
+	// != compares the value as json like ==
+	for _, line := range []string{
+		"?? json $.name != \"cateiru\"",
+		"?? json $.id != 1",
+	} {
+		file, err := easy.ParseHTTPFile(strings.NewReader("GET /\n\n" + line))
+		require.NoError(t, err)
+
+		message = expectFail(t, func(t testing.TB) {
+			file.Requests[0].Assertions[0].Check(t, resp)
+		})
+		require.Contains(t, message, "should not be", line)
+	}
+}
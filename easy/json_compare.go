@@ -0,0 +1,332 @@
+package easy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Option of comparing json.
+type JsonOption func(*jsonComparer)
+
+// Only checks the fields written in the expected value.
+// Fields that exist only in the response are ignored.
+func JsonSubset() JsonOption {
+	return func(c *jsonComparer) {
+		c.subset = true
+	}
+}
+
+// Ignores values at the paths.
+// Paths are JSONPath or JSON Pointer, and JSONPath can use wildcards.
+// Negative indexes are not allowed, because the expected and actual arrays can differ in length.
+//
+// Example:
+//
+//	JsonIgnore("$.createdAt", "$.items[*].id")
+func JsonIgnore(paths ...string) JsonOption {
+	return func(c *jsonComparer) {
+		for _, path := range paths {
+			segments, err := parsePath(path)
+			if err != nil {
+				c.err = err
+				return
+			}
+			for _, segment := range segments {
+				if segment.kind == segmentIndex && segment.index < 0 {
+					c.err = fmt.Errorf("negative index is not allowed in ignored path: %s", path)
+					return
+				}
+			}
+			c.ignore = append(c.ignore, segments)
+		}
+	}
+}
+
+// Compares arrays without regard to the order of elements.
+func JsonIgnoreOrder() JsonOption {
+	return func(c *jsonComparer) {
+		c.ignoreOrder = true
+	}
+}
+
+// Type of json value.
+type JsonType string
+
+const (
+	JsonNull   JsonType = "null"
+	JsonBool   JsonType = "boolean"
+	JsonNumber JsonType = "number"
+	JsonString JsonType = "string"
+	JsonArray  JsonType = "array"
+	JsonObject JsonType = "object"
+)
+
+type jsonComparer struct {
+	subset      bool
+	ignoreOrder bool
+	ignore      [][]pathSegment
+
+	err error
+}
+
+func newJsonComparer(opts []JsonOption) (*jsonComparer, error) {
+	c := &jsonComparer{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, c.err
+}
+
+// Compares the expected object with json body.
+// Returns a list of differences. Empty if they match.
+func (c *jsonComparer) diff(expected any, actual []byte) ([]string, error) {
+	a, err := decodeJson(actual)
+	if err != nil {
+		return nil, fmt.Errorf("response body is not json: %w", err)
+	}
+
+	return c.diffValue([]pathSegment{}, expected, a)
+}
+
+// Compares the expected object with the decoded json value at the path.
+func (c *jsonComparer) diffValue(path []pathSegment, expected any, actual any) ([]string, error) {
+	b, err := json.Marshal(expected)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeJson(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.compare(path, e, actual), nil
+}
+
+func (c *jsonComparer) compare(path []pathSegment, expected any, actual any) []string {
+	if c.ignored(path) {
+		return nil
+	}
+
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			return []string{mismatch(path, expected, actual)}
+		}
+		return c.compareObject(path, e, a)
+	case []any:
+		a, ok := actual.([]any)
+		if !ok {
+			return []string{mismatch(path, expected, actual)}
+		}
+		if c.ignoreOrder {
+			return c.compareArrayIgnoreOrder(path, e, a)
+		}
+		return c.compareArray(path, e, a)
+	case json.Number:
+		a, ok := actual.(json.Number)
+		if !ok || !equalNumber(e, a) {
+			return []string{mismatch(path, expected, actual)}
+		}
+		return nil
+	default:
+		if expected != actual {
+			return []string{mismatch(path, expected, actual)}
+		}
+		return nil
+	}
+}
+
+func (c *jsonComparer) compareObject(path []pathSegment, expected map[string]any, actual map[string]any) []string {
+	diffs := []string{}
+
+	for _, key := range sortedKeys(expected) {
+		p := appendSegment(path, pathSegment{kind: segmentKey, key: key})
+
+		a, ok := actual[key]
+		if !ok {
+			if !c.ignored(p) {
+				diffs = append(diffs, fmt.Sprintf("%s: missing, expected %s", formatJsonPath(p), formatJsonValue(expected[key])))
+			}
+			continue
+		}
+		diffs = append(diffs, c.compare(p, expected[key], a)...)
+	}
+
+	if c.subset {
+		return diffs
+	}
+
+	for _, key := range sortedKeys(actual) {
+		if _, ok := expected[key]; ok {
+			continue
+		}
+		p := appendSegment(path, pathSegment{kind: segmentKey, key: key})
+		if !c.ignored(p) {
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected field %s", formatJsonPath(p), formatJsonValue(actual[key])))
+		}
+	}
+
+	return diffs
+}
+
+func (c *jsonComparer) compareArray(path []pathSegment, expected []any, actual []any) []string {
+	if len(expected) != len(actual) {
+		return []string{fmt.Sprintf("%s: expected %d elements, got %d", formatJsonPath(path), len(expected), len(actual))}
+	}
+
+	diffs := []string{}
+	for i := range expected {
+		p := appendSegment(path, pathSegment{kind: segmentIndex, index: i})
+		diffs = append(diffs, c.compare(p, expected[i], actual[i])...)
+	}
+
+	return diffs
+}
+
+func (c *jsonComparer) compareArrayIgnoreOrder(path []pathSegment, expected []any, actual []any) []string {
+	if len(expected) != len(actual) {
+		return []string{fmt.Sprintf("%s: expected %d elements, got %d", formatJsonPath(path), len(expected), len(actual))}
+	}
+
+	// Elements match only one another, so find the maximum bipartite matching.
+	// Greedy matching fails when an element of JsonSubset matches several.
+	matches := make([][]bool, len(expected))
+	for i, e := range expected {
+		matches[i] = make([]bool, len(actual))
+		for j, a := range actual {
+			p := appendSegment(path, pathSegment{kind: segmentIndex, index: j})
+			matches[i][j] = len(c.compare(p, e, a)) == 0
+		}
+	}
+
+	// index of the expected element matched to the actual element
+	matched := make([]int, len(actual))
+	for j := range matched {
+		matched[j] = -1
+	}
+
+	diffs := []string{}
+	for i, e := range expected {
+		if !augmentMatching(matches, matched, i, make([]bool, len(actual))) {
+			p := appendSegment(path, pathSegment{kind: segmentIndex, index: i})
+			diffs = append(diffs, fmt.Sprintf("%s: no matching element for %s", formatJsonPath(p), formatJsonValue(e)))
+		}
+	}
+
+	return diffs
+}
+
+// Finds an augmenting path from the expected element i (Kuhn's algorithm).
+func augmentMatching(matches [][]bool, matched []int, i int, visited []bool) bool {
+	for j, ok := range matches[i] {
+		if !ok || visited[j] {
+			continue
+		}
+		visited[j] = true
+
+		if matched[j] == -1 || augmentMatching(matches, matched, matched[j], visited) {
+			matched[j] = i
+			return true
+		}
+	}
+	return false
+}
+
+func (c *jsonComparer) ignored(path []pathSegment) bool {
+	for _, pattern := range c.ignore {
+		if matchJsonPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decodes json keeping numbers as json.Number.
+func decodeJson(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid character after top-level value")
+	}
+
+	return v, nil
+}
+
+// Compares numbers by value, so `1`, `1.0` and `1e0` are equal.
+func equalNumber(a json.Number, b json.Number) bool {
+	x, ok := new(big.Rat).SetString(a.String())
+	if !ok {
+		return a == b
+	}
+	y, ok := new(big.Rat).SetString(b.String())
+	if !ok {
+		return a == b
+	}
+
+	return x.Cmp(y) == 0
+}
+
+func jsonTypeOf(v any) JsonType {
+	switch v.(type) {
+	case bool:
+		return JsonBool
+	case json.Number, float64:
+		return JsonNumber
+	case string:
+		return JsonString
+	case []any:
+		return JsonArray
+	case map[string]any:
+		return JsonObject
+	default:
+		return JsonNull
+	}
+}
+
+func mismatch(path []pathSegment, expected any, actual any) string {
+	return fmt.Sprintf("%s: expected %s, got %s", formatJsonPath(path), formatJsonValue(expected), formatJsonValue(actual))
+}
+
+func formatJsonValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func formatJsonDiff(diffs []string) string {
+	return "json mismatch:\n\t" + strings.Join(diffs, "\n\t")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Returns a new slice so that sibling paths don't share the backing array.
+func appendSegment(path []pathSegment, segment pathSegment) []pathSegment {
+	p := make([]pathSegment, len(path), len(path)+1)
+	copy(p, path)
+
+	return append(p, segment)
+}
@@ -0,0 +1,115 @@
+package easy_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func newJsonResponse(body string) *easy.Response {
+	return easy.NewResponse(&http.Response{
+		StatusCode: 200,
+
+		Body: io.NopCloser(strings.NewReader(body)),
+	})
+}
+
+func TestEqJsonSemantic(t *testing.T) {
+	t.Run("key order and whitespace", func(t *testing.T) {
+		r := newJsonResponse("{\n  \"b\": 2,\n  \"a\": \"x\"\n}\n")
+
+		r.EqJson(t, map[string]any{"a": "x", "b": 2})
+	})
+
+	t.Run("float formatting", func(t *testing.T) {
+		r := newJsonResponse(`{"price": 1.50, "count": 1e1}`)
+
+		r.EqJson(t, map[string]any{"price": 1.5, "count": 10})
+	})
+
+	t.Run("raw message", func(t *testing.T) {
+		r := newJsonResponse(`[1, 2, 3]`)
+
+		r.EqJson(t, json.RawMessage(`[1,2,3]`))
+	})
+}
+
+func TestEqJsonOptions(t *testing.T) {
+	body := `{
+		"id": "0c7f1d7a-3d2f-4f86-9c59-93b5e5a3e2b4",
+		"name": "cateiru",
+		"createdAt": "2022-10-01T00:00:00Z",
+		"tags": ["b", "a", "c"],
+		"items": [{"id": 10, "name": "x"}, {"id": 11, "name": "y"}]
+	}`
+
+	t.Run("subset", func(t *testing.T) {
+		r := newJsonResponse(body)
+
+		r.ContainsJson(t, map[string]any{
+			"name":  "cateiru",
+			"items": []any{map[string]any{"name": "x"}, map[string]any{"name": "y"}},
+		})
+	})
+
+	t.Run("ignore paths", func(t *testing.T) {
+		r := newJsonResponse(body)
+
+		r.EqJson(t, map[string]any{
+			"name":  "cateiru",
+			"tags":  []string{"b", "a", "c"},
+			"items": []any{map[string]any{"name": "x"}, map[string]any{"name": "y"}},
+		}, easy.JsonIgnore("$.id", "$.createdAt", "$.items[*].id"))
+	})
+
+	t.Run("negative index in ignored path", func(t *testing.T) {
+		r := newJsonResponse(body)
+
+		msg := expectFail(t, func(t testing.TB) {
+			r.EqJson(t, map[string]any{}, easy.JsonIgnore("$.items[-1].id"))
+		})
+		require.Contains(t, msg, "negative index is not allowed in ignored path: $.items[-1].id")
+	})
+
+	t.Run("ignore order", func(t *testing.T) {
+		r := newJsonResponse(body)
+
+		r.ContainsJson(t, map[string]any{
+			"tags": []string{"a", "b", "c"},
+		}, easy.JsonIgnoreOrder())
+	})
+
+	t.Run("ignore order with subset elements", func(t *testing.T) {
+		r := newJsonResponse(`[{"a":1,"b":2},{"a":1}]`)
+
+		// {"a":1} matches both elements
+		r.ContainsJson(t, []any{
+			map[string]any{"a": 1},
+			map[string]any{"a": 1, "b": 2},
+		}, easy.JsonIgnoreOrder())
+
+		msg := expectFail(t, func(t testing.TB) {
+			r.ContainsJson(t, []any{
+				map[string]any{"a": 1, "b": 2},
+				map[string]any{"a": 1, "b": 2},
+			}, easy.JsonIgnoreOrder())
+		})
+		require.Contains(t, msg, `$[1]: no matching element for {"a":1,"b":2}`)
+	})
+}
+
+func TestMockContainsJson(t *testing.T) {
+	m, err := easy.NewMock("/", http.MethodGet, "")
+	require.NoError(t, err)
+
+	m.Handler(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"nya": "aaaa", "extra": true})
+	})
+
+	m.ContainsJson(t, JsonData{Nya: "aaaa"})
+}
@@ -0,0 +1,91 @@
+package easy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Option of decoding json.
+type DecodeOption func(*decodeConfig)
+
+type decodeConfig struct {
+	disallowUnknownFields bool
+	useNumber             bool
+	requireContentType    bool
+}
+
+// Fail if the json has fields that are not in the type.
+func DisallowUnknownFields() DecodeOption {
+	return func(c *decodeConfig) {
+		c.disallowUnknownFields = true
+	}
+}
+
+// Decode numbers in `any` as json.Number instead of float64.
+func UseNumber() DecodeOption {
+	return func(c *decodeConfig) {
+		c.useNumber = true
+	}
+}
+
+// Fail if the Content-Type of the response is not json.
+// `application/json` and `+json` types (e.g. `application/problem+json`) are json.
+func RequireJsonContentType() DecodeOption {
+	return func(c *decodeConfig) {
+		c.requireContentType = true
+	}
+}
+
+// Decode json body of Response or MockHandler into T.
+// Fails the test if it cannot be decoded.
+//
+// Example:
+//
+//	user := easy.Json[User](t, resp, easy.DisallowUnknownFields())
+func Json[T any](t testing.TB, r Result, opts ...DecodeOption) T {
+	t.Helper()
+	t = withDump(t, r)
+
+	config := &decodeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.requireContentType {
+		contentType := r.header().Get("Content-Type")
+		require.True(t, isJsonContentType(contentType), "Content-Type is not json: %q", contentType)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(r.bodyBytes()))
+	if config.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if config.useNumber {
+		decoder.UseNumber()
+	}
+
+	var v T
+	err := decoder.Decode(&v)
+	require.NoError(t, err, "failed to decode json body")
+
+	_, err = decoder.Token()
+	require.True(t, errors.Is(err, io.EOF), "json body has data after the top-level value")
+
+	return v
+}
+
+func isJsonContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,46 @@
+package easy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonDecode(t *testing.T) {
+	t.Run("Response", func(t *testing.T) {
+		r := newJsonResponse(`{"nya": "aaaa"}`)
+
+		data := easy.Json[JsonData](t, r)
+		require.Equal(t, JsonData{Nya: "aaaa"}, data)
+	})
+
+	t.Run("MockHandler", func(t *testing.T) {
+		m, err := easy.NewMock("/", http.MethodGet, "")
+		require.NoError(t, err)
+
+		m.Handler(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+			w.Write([]byte(`[{"nya": "a"}, {"nya": "b"}]`))
+		})
+
+		data := easy.Json[[]JsonData](t, m, easy.RequireJsonContentType(), easy.DisallowUnknownFields())
+		require.Equal(t, []JsonData{{Nya: "a"}, {Nya: "b"}}, data)
+	})
+
+	t.Run("UseNumber", func(t *testing.T) {
+		r := newJsonResponse(`{"id": 12345678901234567890}`)
+
+		data := easy.Json[map[string]any](t, r, easy.UseNumber())
+		require.Equal(t, json.Number("12345678901234567890"), data["id"])
+	})
+
+	t.Run("read body many times", func(t *testing.T) {
+		r := newJsonResponse(`{"nya": "aaaa"}`)
+
+		easy.Json[JsonData](t, r)
+		r.EqJson(t, JsonData{Nya: "aaaa"})
+	})
+}
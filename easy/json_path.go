@@ -0,0 +1,341 @@
+package easy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	// `.name` or `['name']`
+	segmentKey segmentKind = iota
+	// `[0]`
+	segmentIndex
+	// `.*` or `[*]`
+	segmentWildcard
+	// `..`, selects the value and all of its descendants
+	segmentRecursive
+	// reference token of JSON Pointer, a member name or an array index
+	segmentToken
+)
+
+// A step of the path to a value in the JSON document.
+type pathSegment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+var identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+
+// Parses a path to values in JSON.
+// Path starting with `$` is JSONPath, otherwise it is JSON Pointer (RFC 6901).
+//
+// Example:
+//
+//	parsePath("$.items[0].id")
+//	parsePath("/items/0/id")
+func parsePath(path string) ([]pathSegment, error) {
+	if strings.HasPrefix(path, "$") {
+		return parseJsonPath(path)
+	}
+	return parseJsonPointer(path)
+}
+
+// Parses a JSON Pointer (RFC 6901) like `/items/0/id`.
+// The empty string points to the whole document.
+func parseJsonPointer(pointer string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+	if pointer == "" {
+		return segments, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer must start with `/`: %s", pointer)
+	}
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(token, "~1", "/")
+		token = strings.ReplaceAll(token, "~0", "~")
+
+		segments = append(segments, pathSegment{kind: segmentToken, key: token})
+	}
+
+	return segments, nil
+}
+
+// Parses a JSONPath like `$.items[0].id`.
+//
+// Supported syntax:
+//   - `$` root
+//   - `.name` and `['name']` (or `["name"]`) member
+//   - `[0]` array index, negative index counts from the end
+//   - `.*` and `[*]` wildcard
+//   - `..name` recursive descent
+func parseJsonPath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path must start with `$`: %s", path)
+	}
+
+	segments := []pathSegment{}
+	rest := path[1:]
+
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+
+			if strings.HasPrefix(rest, ".") {
+				segments = append(segments, pathSegment{kind: segmentRecursive})
+				rest = rest[1:]
+				if strings.HasPrefix(rest, "[") {
+					continue
+				}
+			}
+
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("empty member name in json path: %s", path)
+			}
+			rest = rest[end:]
+
+			if name == "*" {
+				segments = append(segments, pathSegment{kind: segmentWildcard})
+			} else {
+				segments = append(segments, pathSegment{kind: segmentKey, key: name})
+			}
+		case '[':
+			end := closingBracket(rest)
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed `[` in json path: %s", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			segment, err := parseBracket(inner)
+			if err != nil {
+				return nil, fmt.Errorf("%w in json path: %s", err, path)
+			}
+			segments = append(segments, segment)
+		default:
+			return nil, fmt.Errorf("unexpected %q in json path: %s", rest[0], path)
+		}
+	}
+
+	return segments, nil
+}
+
+// Returns the position of `]` that closes the bracket at rest[0].
+// Brackets inside quoted names are skipped.
+func closingBracket(rest string) int {
+	var quote byte
+	for i := 1; i < len(rest); i++ {
+		switch {
+		case quote != 0 && rest[i] == '\\':
+			i++
+		case quote != 0 && rest[i] == quote:
+			quote = 0
+		case quote == 0 && (rest[i] == '\'' || rest[i] == '"'):
+			quote = rest[i]
+		case quote == 0 && rest[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseBracket(inner string) (pathSegment, error) {
+	if inner == "*" {
+		return pathSegment{kind: segmentWildcard}, nil
+	}
+
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		name := inner[1 : len(inner)-1]
+		name = strings.ReplaceAll(name, `\`+string(inner[0]), string(inner[0]))
+		name = strings.ReplaceAll(name, `\\`, `\`)
+		return pathSegment{kind: segmentKey, key: name}, nil
+	}
+
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return pathSegment{}, fmt.Errorf("illegal index `[%s]`", inner)
+	}
+	return pathSegment{kind: segmentIndex, index: index}, nil
+}
+
+// Formats segments as a JSONPath.
+func formatJsonPath(segments []pathSegment) string {
+	b := strings.Builder{}
+	b.WriteString("$")
+
+	for _, s := range segments {
+		switch s.kind {
+		case segmentKey:
+			if identPattern.MatchString(s.key) {
+				b.WriteString("." + s.key)
+			} else {
+				b.WriteString("[" + strconv.Quote(s.key) + "]")
+			}
+		case segmentIndex:
+			b.WriteString("[" + strconv.Itoa(s.index) + "]")
+		case segmentWildcard:
+			b.WriteString("[*]")
+		case segmentRecursive:
+			b.WriteString("..")
+		case segmentToken:
+			b.WriteString("[" + strconv.Quote(s.key) + "]")
+		}
+	}
+
+	return b.String()
+}
+
+// Checks whether a concrete path is selected by the pattern.
+// The pattern may contain wildcards and recursive descent.
+func matchJsonPath(pattern []pathSegment, path []pathSegment) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0].kind == segmentRecursive {
+		for i := 0; i <= len(path); i++ {
+			if matchJsonPath(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 || !matchSegment(pattern[0], path[0]) {
+		return false
+	}
+	return matchJsonPath(pattern[1:], path[1:])
+}
+
+func matchSegment(pattern pathSegment, segment pathSegment) bool {
+	switch pattern.kind {
+	case segmentWildcard:
+		return true
+	case segmentKey:
+		return segment.kind == segmentKey && segment.key == pattern.key
+	case segmentIndex:
+		return segment.kind == segmentIndex && segment.index == pattern.index
+	case segmentToken:
+		switch segment.kind {
+		case segmentKey:
+			return segment.key == pattern.key
+		case segmentIndex:
+			return strconv.Itoa(segment.index) == pattern.key
+		}
+	}
+	return false
+}
+
+// A value selected by the path.
+type jsonMatch struct {
+	path  []pathSegment
+	value any
+}
+
+// Selects values in the decoded json document.
+func selectJson(doc any, segments []pathSegment) []jsonMatch {
+	matches := []jsonMatch{{path: []pathSegment{}, value: doc}}
+
+	for _, segment := range segments {
+		next := []jsonMatch{}
+		for _, m := range matches {
+			next = append(next, selectSegment(m, segment)...)
+		}
+		matches = next
+	}
+
+	return matches
+}
+
+func selectSegment(m jsonMatch, segment pathSegment) []jsonMatch {
+	switch v := m.value.(type) {
+	case map[string]any:
+		switch segment.kind {
+		case segmentKey, segmentToken:
+			if child, ok := v[segment.key]; ok {
+				return []jsonMatch{childMatch(m, pathSegment{kind: segmentKey, key: segment.key}, child)}
+			}
+		case segmentWildcard:
+			matches := []jsonMatch{}
+			for _, key := range sortedKeys(v) {
+				matches = append(matches, childMatch(m, pathSegment{kind: segmentKey, key: key}, v[key]))
+			}
+			return matches
+		case segmentRecursive:
+			return descendants(m)
+		}
+	case []any:
+		switch segment.kind {
+		case segmentIndex, segmentToken:
+			index := segment.index
+			if segment.kind == segmentToken {
+				i, err := strconv.Atoi(segment.key)
+				if err != nil || i < 0 {
+					return nil
+				}
+				index = i
+			}
+			if index < 0 {
+				index += len(v)
+			}
+			if index >= 0 && index < len(v) {
+				return []jsonMatch{childMatch(m, pathSegment{kind: segmentIndex, index: index}, v[index])}
+			}
+		case segmentWildcard:
+			matches := []jsonMatch{}
+			for i, child := range v {
+				matches = append(matches, childMatch(m, pathSegment{kind: segmentIndex, index: i}, child))
+			}
+			return matches
+		case segmentRecursive:
+			return descendants(m)
+		}
+	default:
+		if segment.kind == segmentRecursive {
+			return []jsonMatch{m}
+		}
+	}
+
+	return nil
+}
+
+// Returns the value itself and all of its descendants.
+func descendants(m jsonMatch) []jsonMatch {
+	matches := []jsonMatch{m}
+
+	children := selectSegment(m, pathSegment{kind: segmentWildcard})
+	for _, child := range children {
+		matches = append(matches, descendants(child)...)
+	}
+
+	return matches
+}
+
+func childMatch(parent jsonMatch, segment pathSegment, value any) jsonMatch {
+	return jsonMatch{
+		path:  appendSegment(parent.path, segment),
+		value: value,
+	}
+}
+
+// Whether the path can select more than one value.
+func isIndefinitePath(segments []pathSegment) bool {
+	for _, s := range segments {
+		if s.kind == segmentWildcard || s.kind == segmentRecursive {
+			return true
+		}
+	}
+	return false
+}
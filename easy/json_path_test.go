@@ -0,0 +1,92 @@
+package easy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+const pathTestBody = `{
+	"name": "cateiru",
+	"a/b": 1,
+	"m~n": 2,
+	"items": [
+		{"id": 10, "name": "x", "tags": ["a"]},
+		{"id": 11, "name": "y", "tags": []}
+	],
+	"meta": {"total": 2, "next": null, "ok": true}
+}`
+
+func TestJsonPath(t *testing.T) {
+	r := newJsonResponse(pathTestBody)
+
+	t.Run("EqJsonPath", func(t *testing.T) {
+		r.EqJsonPath(t, "$.name", "cateiru")
+		r.EqJsonPath(t, "$.items[0].id", 10)
+		r.EqJsonPath(t, "$.items[-1].name", "y")
+		r.EqJsonPath(t, "$['items'][1]['id']", 11)
+		r.EqJsonPath(t, "$.meta", map[string]any{"total": 2, "next": nil, "ok": true})
+		r.EqJsonPath(t, "$.meta", map[string]any{"total": 2}, easy.JsonSubset())
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		r.EqJsonPath(t, "$.items[*].id", []int{10, 11})
+		r.EqJsonPath(t, "$..id", []int{10, 11})
+		r.JsonPathLen(t, "$.items[*].tags[*]", 1)
+	})
+
+	t.Run("JSON Pointer", func(t *testing.T) {
+		r.EqJsonPath(t, "/items/1/id", 11)
+		r.EqJsonPath(t, "/a~1b", 1)
+		r.EqJsonPath(t, "/m~0n", 2)
+		r.EqJsonPath(t, "", json.RawMessage(pathTestBody))
+	})
+
+	t.Run("JsonPathType", func(t *testing.T) {
+		r.JsonPathType(t, "$.name", easy.JsonString)
+		r.JsonPathType(t, "$.items", easy.JsonArray)
+		r.JsonPathType(t, "$.items[*].id", easy.JsonNumber)
+		r.JsonPathType(t, "$.meta", easy.JsonObject)
+		r.JsonPathType(t, "$.meta.next", easy.JsonNull)
+		r.JsonPathType(t, "/meta/ok", easy.JsonBool)
+	})
+
+	t.Run("JsonPathLen", func(t *testing.T) {
+		r.JsonPathLen(t, "$.items", 2)
+		r.JsonPathLen(t, "$.items[1].tags", 0)
+		r.JsonPathLen(t, "$.meta", 3)
+		r.JsonPathLen(t, "$.name", 7)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		r.HasJsonPath(t, "$.meta.next")
+		r.HasJsonPath(t, "/items/0")
+		r.NoJsonPath(t, "$.items[2]")
+		r.NoJsonPath(t, "$.meta.prev")
+		r.NoJsonPath(t, "/items/x")
+	})
+}
+
+func TestMockJsonPath(t *testing.T) {
+	m, err := easy.NewMock("/", http.MethodGet, "")
+	require.NoError(t, err)
+
+	m.Handler(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pathTestBody))
+	})
+
+	m.EqJsonPath(t, "$.items[0].name", "x")
+	m.JsonPathType(t, "$.items[0].tags", easy.JsonArray)
+	m.JsonPathLen(t, "/items", 2)
+	m.HasJsonPath(t, "$.meta.total")
+	m.NoJsonPath(t, "$.meta.total.value")
+}
+
+func TestJsonIgnorePointer(t *testing.T) {
+	r := newJsonResponse(`{"id": 1, "name": "x"}`)
+
+	r.EqJson(t, map[string]any{"name": "x"}, easy.JsonIgnore("/id"))
+}
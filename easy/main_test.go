@@ -1,6 +1,13 @@
 package easy_test
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
 
 type JsonData struct {
 	Nya string `json:"nya"`
@@ -9,3 +16,101 @@ type JsonData struct {
 func Handler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("OK"))
 }
+
+// testing.TB that records failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+
+	mu       sync.Mutex
+	failed   bool
+	messages []string
+	cleanups []func()
+}
+
+func (c *fakeT) Helper() {}
+
+func (c *fakeT) Name() string {
+	return "fakeT"
+}
+
+func (c *fakeT) Errorf(format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failed = true
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+func (c *fakeT) Error(args ...any) {
+	c.Errorf("%s", fmt.Sprint(args...))
+}
+
+func (c *fakeT) Fatalf(format string, args ...any) {
+	c.Errorf(format, args...)
+	c.FailNow()
+}
+
+func (c *fakeT) Fail() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failed = true
+}
+
+func (c *fakeT) FailNow() {
+	c.Fail()
+	runtime.Goexit()
+}
+
+func (c *fakeT) Failed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.failed
+}
+
+func (c *fakeT) Log(args ...any) {
+	c.Logf("%s", fmt.Sprint(args...))
+}
+
+func (c *fakeT) Logf(format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+func (c *fakeT) Cleanup(f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cleanups = append(c.cleanups, f)
+}
+
+func (c *fakeT) runCleanups() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+	}
+}
+
+// Runs f with fakeT, and returns failure messages and logs.
+// Fails the test if f does not fail.
+func expectFail(t *testing.T, f func(t testing.TB)) string {
+	t.Helper()
+
+	fake := &fakeT{}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer fake.runCleanups()
+		f(fake)
+	}()
+	<-done
+
+	if !fake.Failed() {
+		t.Fatal("expected to fail, but succeeded")
+	}
+
+	return strings.Join(fake.messages, "\n")
+}
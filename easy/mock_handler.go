@@ -13,7 +13,6 @@ import (
 	"testing"
 
 	"github.com/labstack/echo/v4"
-	"github.com/stretchr/testify/require"
 )
 
 type MockHandler struct {
@@ -21,6 +20,9 @@ type MockHandler struct {
 	R *http.Request
 
 	Cookies []string
+
+	// nil is DefaultDumpSettings
+	dump *DumpSettings
 }
 
 // Create mock objects.
@@ -50,7 +52,13 @@ func NewMockReader(path string, method string, body io.Reader) (*MockHandler, er
 		return nil, errors.New("illegal path case. path: " + path)
 	}
 
+	body, getBody, err := snapshotBody(body)
+	if err != nil {
+		return nil, err
+	}
+
 	r := httptest.NewRequest(method, path, body)
+	r.GetBody = getBody
 	w := httptest.NewRecorder()
 
 	return &MockHandler{
@@ -59,6 +67,38 @@ func NewMockReader(path string, method string, body io.Reader) (*MockHandler, er
 	}, nil
 }
 
+// Makes the body readable again after the handler reads it, like http.NewRequest.
+// Curl and dumps on failure read it by GetBody.
+func snapshotBody(body io.Reader) (io.Reader, func() (io.ReadCloser, error), error) {
+	switch v := body.(type) {
+	case nil:
+		return nil, nil, nil
+	case *bytes.Buffer:
+		buf := v.Bytes()
+		return body, func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(buf)), nil
+		}, nil
+	case *bytes.Reader:
+		snapshot := *v
+		return body, func() (io.ReadCloser, error) {
+			r := snapshot
+			return io.NopCloser(&r), nil
+		}, nil
+	case *strings.Reader:
+		snapshot := *v
+		return body, func() (io.ReadCloser, error) {
+			r := snapshot
+			return io.NopCloser(&r), nil
+		}, nil
+	}
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshotBody(bytes.NewReader(b))
+}
+
 // Post json. Use the POST or PUT method.
 func NewJson(path string, method string, data any) (*MockHandler, error) {
 	b, err := json.Marshal(data)
@@ -106,6 +146,12 @@ func (c *MockHandler) SetAddr(addr string) {
 	c.R.RemoteAddr = addr
 }
 
+// Set the dump logged when an assertion fails, instead of the package defaults.
+func (c *MockHandler) SetDump(settings DumpSettings) {
+	settings.RedactHeaders = append([]string{}, settings.RedactHeaders...)
+	c.dump = &settings
+}
+
 // Including cookies in the request
 func (c *MockHandler) Cookie(cookies []*http.Cookie) {
 
@@ -136,26 +182,38 @@ func (c *MockHandler) Echo() echo.Context {
 }
 
 // Check if request success
-func (c *MockHandler) Ok(t *testing.T) {
+func (c *MockHandler) Ok(t testing.TB) {
+	t.Helper()
+
 	c.Status(t, http.StatusOK)
 }
 
 // Check response status code
-func (c *MockHandler) Status(t *testing.T, status int) {
-	require.Equal(t, c.W.Code, status)
+func (c *MockHandler) Status(t testing.TB, status int) {
+	t.Helper()
+
+	checkStatus(t, c, status)
 }
 
 // Compare response body
-func (c *MockHandler) EqBody(t *testing.T, body string) {
-	require.Equal(t, c.W.Body.String(), body)
+func (c *MockHandler) EqBody(t testing.TB, body string) {
+	t.Helper()
+
+	checkEqBody(t, c, body)
 }
 
 // Compare response body written json
-func (c *MockHandler) EqJson(t *testing.T, obj any) {
-	bytes, err := json.Marshal(obj)
-	require.NoError(t, err)
+func (c *MockHandler) EqJson(t testing.TB, obj any, opts ...JsonOption) {
+	t.Helper()
+
+	checkEqJson(t, c, obj, opts)
+}
+
+// Check the response json contains at least the fields of obj
+func (c *MockHandler) ContainsJson(t testing.TB, obj any, opts ...JsonOption) {
+	t.Helper()
 
-	require.Equal(t, c.W.Body.Bytes(), bytes)
+	checkEqJson(t, c, obj, append([]JsonOption{JsonSubset()}, opts...))
 }
 
 // Prase json body
@@ -185,3 +243,134 @@ func (c *MockHandler) FindCookie(name string) *http.Cookie {
 	}
 	return nil
 }
+
+// Compare the json value at the path.
+// The path is JSONPath (`$.items[0].id`) or JSON Pointer (`/items/0/id`).
+// If JSONPath contains wildcards, the selected values are compared as an array.
+func (c *MockHandler) EqJsonPath(t testing.TB, path string, value any, opts ...JsonOption) {
+	t.Helper()
+
+	checkEqJsonPath(t, c, path, value, opts)
+}
+
+// Check the type of the json value at the path
+func (c *MockHandler) JsonPathType(t testing.TB, path string, typ JsonType) {
+	t.Helper()
+
+	checkJsonPathType(t, c, path, typ)
+}
+
+// Check the length of the array, object or string at the path.
+// If JSONPath contains wildcards, checks the number of selected values.
+func (c *MockHandler) JsonPathLen(t testing.TB, path string, length int) {
+	t.Helper()
+
+	checkJsonPathLen(t, c, path, length)
+}
+
+// Check the json value exists at the path
+func (c *MockHandler) HasJsonPath(t testing.TB, path string) {
+	t.Helper()
+
+	checkHasJsonPath(t, c, path)
+}
+
+// Check the json value does not exist at the path
+func (c *MockHandler) NoJsonPath(t testing.TB, path string) {
+	t.Helper()
+
+	checkNoJsonPath(t, c, path)
+}
+
+// Compare status, selected headers and body with the golden file `testdata/<name>.golden`.
+// Run `EASY_UPDATE_SNAPSHOTS=1 go test` to create or update golden files.
+//
+// Example:
+//
+//	m.Snapshot(t, "users/get", easy.SnapshotHeaders("Content-Type"), easy.SnapshotMaskUUID())
+func (c *MockHandler) Snapshot(t testing.TB, name string, opts ...SnapshotOption) {
+	t.Helper()
+
+	checkSnapshot(t, c, name, opts)
+}
+
+// Returns soft assertions.
+// Failures don't stop the test, and are reported together by Done or at the end of the test.
+//
+// Example:
+//
+//	m.Assert(t).Ok().EqHeader("Content-Type", "application/json").EqJson(obj).Done()
+func (c *MockHandler) Assert(t testing.TB) *Assertion {
+	return newAssertion(t, c, true)
+}
+
+// Returns chainable assertions that stop the test at the first failure.
+func (c *MockHandler) Require(t testing.TB) *Assertion {
+	return newAssertion(t, c, false)
+}
+
+// Check the first value of the header
+func (c *MockHandler) EqHeader(t testing.TB, key string, value string) {
+	t.Helper()
+
+	checkEqHeader(t, c, key, value)
+}
+
+// Check all values of the header in order
+func (c *MockHandler) EqHeaderValues(t testing.TB, key string, values ...string) {
+	t.Helper()
+
+	checkEqHeaderValues(t, c, key, values)
+}
+
+// Check the first value of the header contains substr
+func (c *MockHandler) ContainsHeader(t testing.TB, key string, substr string) {
+	t.Helper()
+
+	checkContainsHeader(t, c, key, substr)
+}
+
+// Check the first value of the header matches the regular expression
+func (c *MockHandler) MatchHeader(t testing.TB, key string, pattern string) {
+	t.Helper()
+
+	checkMatchHeader(t, c, key, pattern)
+}
+
+// Check the header is set
+func (c *MockHandler) HasHeader(t testing.TB, key string) {
+	t.Helper()
+
+	checkHasHeader(t, c, key)
+}
+
+// Check the header is not set
+func (c *MockHandler) NoHeader(t testing.TB, key string) {
+	t.Helper()
+
+	checkNoHeader(t, c, key)
+}
+
+func (c *MockHandler) statusCode() int {
+	return c.W.Code
+}
+
+func (c *MockHandler) header() http.Header {
+	return c.Response().Header
+}
+
+func (c *MockHandler) request() *http.Request {
+	return c.R
+}
+
+func (c *MockHandler) dumpSettings() DumpSettings {
+	return dumpSettingsOf(c.dump)
+}
+
+func (c *MockHandler) bodyBytes() []byte {
+	if b := c.W.Body.Bytes(); b != nil {
+		return b
+	}
+	// nil is for the body not available.
+	return []byte{}
+}
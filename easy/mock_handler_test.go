@@ -3,6 +3,7 @@ package easy_test
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -26,6 +27,21 @@ func EchoHandler(c echo.Context) error {
 	return c.String(http.StatusOK, "OK")
 }
 
+// The body can be read again by GetBody.
+// GetBody is cleared to compare with httptest.NewRequest.
+func requireGetBody(t *testing.T, m *easy.MockHandler, expected string) {
+	t.Helper()
+
+	require.NotNil(t, m.R.GetBody)
+	body, err := m.R.GetBody()
+	require.NoError(t, err)
+	b, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+
+	m.R.GetBody = nil
+}
+
 func TestNewMock(t *testing.T) {
 	successCases := []NewArgs{
 		{
@@ -97,6 +113,7 @@ func TestNewMock(t *testing.T) {
 				r := httptest.NewRequest(http.MethodGet, c.Path, strings.NewReader(c.Body))
 				w := httptest.NewRecorder()
 
+				requireGetBody(t, m, c.Body)
 				require.Equal(t, m, &easy.MockHandler{
 					R: r,
 					W: w,
@@ -126,6 +143,7 @@ func TestNewMock(t *testing.T) {
 				r := httptest.NewRequest(http.MethodGet, c.Path, strings.NewReader(c.Body))
 				w := httptest.NewRecorder()
 
+				requireGetBody(t, m, c.Body)
 				require.Equal(t, m, &easy.MockHandler{
 					R: r,
 					W: w,
@@ -158,6 +176,7 @@ func TestNewMock(t *testing.T) {
 
 			r.Header.Set("content-type", "application/json")
 
+			requireGetBody(t, m, string(b))
 			require.Equal(t, m, &easy.MockHandler{
 				R: r,
 				W: w,
@@ -446,3 +465,25 @@ func TestResponse(t *testing.T) {
 
 	require.NotNil(t, resp)
 }
+
+func TestMockHeader(t *testing.T) {
+	m, err := easy.NewMock("/", http.MethodGet, "")
+	require.NoError(t, err)
+
+	m.Handler(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Add("Vary", "Origin")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header()["x-request-id"] = []string{"12345"}
+
+		w.Write([]byte("OK"))
+	})
+
+	m.EqHeader(t, "content-type", "text/plain; charset=utf-8")
+	m.EqHeaderValues(t, "Vary", "Origin", "Accept-Encoding")
+	m.ContainsHeader(t, "Content-Type", "text/plain")
+	m.MatchHeader(t, "Content-Type", `charset=utf-\d`)
+	m.EqHeader(t, "X-Request-Id", "12345")
+	m.HasHeader(t, "x-request-id")
+	m.NoHeader(t, "Set-Cookie")
+}
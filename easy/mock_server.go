@@ -2,48 +2,148 @@ package easy
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
-var client = new(http.Client)
-
 type MockServer struct {
 	Server *httptest.Server
+	// Client of this server.
+	// It has its own cookie jar, so Set-Cookie of responses are sent with following requests.
+	Client *http.Client
+	// Default headers sent with every request.
+	// Use RequestOption such as WithHeader to set headers for one request.
 	Header *http.Header
 
 	Cookies []string
+
+	// Certificate authority of the mutual TLS server.
+	// It is nil for other servers.
+	CA *CertAuthority
+
+	redirectPolicy RedirectPolicy
+	config         *serverConfig
+	tracker        *connTracker
+
+	// Unread responses, closed at cleanup if FailOnLeak is set
+	mu        sync.Mutex
+	responses []*Response
 }
 
 // Start mock server
-func NewMockServer(handler http.Handler) *MockServer {
-	server := httptest.NewServer(handler)
+func NewMockServer(handler http.Handler, opts ...ServerOption) *MockServer {
+	config := newServerConfig(opts)
+	tracker := newConnTracker()
+	server := newUnstartedServer(handler, config, tracker)
+
+	client := newClient()
+	if config.http2 {
+		server.Config.Handler = h2c.NewHandler(server.Config.Handler, &http2.Server{})
+		client.Transport = newH2CTransport()
+	}
+	server.Start()
 
-	return &MockServer{
+	s := &MockServer{
 		Server: server,
+		Client: client,
 		Header: &http.Header{},
+
+		redirectPolicy: FollowRedirects,
+		config:         config,
+		tracker:        tracker,
 	}
+	s.Client.CheckRedirect = s.checkRedirect
+
+	return s
 }
 
 // Start mock server with TLS mode
-func NewMockTLSServer(handler http.Handler) *MockServer {
-	server := httptest.NewTLSServer(handler)
+func NewMockTLSServer(handler http.Handler, opts ...ServerOption) *MockServer {
+	config := newServerConfig(opts)
+	tracker := newConnTracker()
+	server := newUnstartedServer(handler, config, tracker)
+	server.EnableHTTP2 = config.http2
+	server.StartTLS()
+
+	// Client of httptest trusts the certificate of the server.
+	client := server.Client()
+	client.Jar = newJar()
 
-	return &MockServer{
+	s := &MockServer{
 		Server: server,
+		Client: client,
+		Header: &http.Header{},
+
+		redirectPolicy: FollowRedirects,
+		config:         config,
+		tracker:        tracker,
 	}
+	s.Client.CheckRedirect = s.checkRedirect
+
+	return s
+}
+
+// Start mock server, and close it when the test finishes.
+// No need to call Close.
+//
+// Example:
+//
+//	s := easy.StartMockServer(t, mux, easy.FailOnLeak())
+func StartMockServer(t testing.TB, handler http.Handler, opts ...ServerOption) *MockServer {
+	t.Helper()
+
+	s := NewMockServer(handler, opts...)
+	t.Cleanup(func() { s.cleanup(t) })
+
+	return s
+}
+
+// Start mock server with TLS mode, and close it when the test finishes.
+// No need to call Close.
+func StartMockTLSServer(t testing.TB, handler http.Handler, opts ...ServerOption) *MockServer {
+	t.Helper()
+
+	s := NewMockTLSServer(handler, opts...)
+	t.Cleanup(func() { s.cleanup(t) })
+
+	return s
 }
 
 // close server
 func (c *MockServer) Close() {
 	c.Server.Close()
+	c.Client.CloseIdleConnections()
+}
+
+// Closes the server, and checks leaks if FailOnLeak is set.
+func (c *MockServer) cleanup(t testing.TB) {
+	t.Helper()
+
+	if c.config.failOnLeak {
+		c.closeResponses()
+		c.Client.CloseIdleConnections()
+
+		if err := c.tracker.waitLeaks(leakWaitTimeout); err != nil {
+			t.Errorf("mock server leaks at shutdown: %s", err)
+			c.Server.CloseClientConnections()
+		}
+	}
+
+	c.Close()
 }
 
 // convert to mock server url
@@ -51,6 +151,8 @@ func (c *MockServer) URL(path string) string {
 	return c.Server.URL + path
 }
 
+// Including cookies in every request.
+// These cookies are static, use SetJarCookies for cookies that can be updated by Set-Cookie.
 func (c *MockServer) Cookie(cookies []*http.Cookie) {
 	for _, cookie := range cookies {
 		c.Cookies = append(c.Cookies, cookie.String())
@@ -59,73 +161,210 @@ func (c *MockServer) Cookie(cookies []*http.Cookie) {
 	c.Header.Set("cookie", strings.Join(c.Cookies, "; "))
 }
 
+// Returns cookies in the cookie jar that are sent to the path.
+// Only Name and Value of the cookie are set.
+func (c *MockServer) JarCookies(t testing.TB, path string) []*http.Cookie {
+	t.Helper()
+
+	u, err := url.Parse(c.URL(path))
+	require.NoError(t, err)
+
+	return c.Client.Jar.Cookies(u)
+}
+
+// Set cookies to the cookie jar as if the path responded with Set-Cookie.
+func (c *MockServer) SetJarCookies(t testing.TB, path string, cookies []*http.Cookie) {
+	t.Helper()
+
+	u, err := url.Parse(c.URL(path))
+	require.NoError(t, err)
+
+	c.Client.Jar.SetCookies(u, cookies)
+}
+
+// Remove all cookies in the cookie jar.
+func (c *MockServer) ClearJar() {
+	c.Client.Jar = newJar()
+}
+
 // GET Request
-func (c *MockServer) Get(t *testing.T, path string) *Response {
-	return c.Do(t, path, http.MethodGet, nil)
+func (c *MockServer) Get(t testing.TB, path string, opts ...RequestOption) *Response {
+	t.Helper()
+
+	return c.Do(t, path, http.MethodGet, nil, opts...)
 }
 
 // Get Request and check status 200
-func (c *MockServer) GetOK(t *testing.T, path string) *Response {
-	resp := c.Get(t, path)
+func (c *MockServer) GetOK(t testing.TB, path string, opts ...RequestOption) *Response {
+	t.Helper()
+
+	resp := c.Get(t, path, opts...)
 	resp.Ok(t)
 
 	return resp
 }
 
 // POST Requests
-func (c *MockServer) Post(t *testing.T, path string, contentType string, body io.Reader) *Response {
-	c.Header.Add("Content-Type", contentType)
+func (c *MockServer) Post(t testing.TB, path string, contentType string, body io.Reader, opts ...RequestOption) *Response {
+	t.Helper()
+
+	opts = append([]RequestOption{WithHeader("Content-Type", contentType)}, opts...)
 
-	return c.Do(t, path, http.MethodPost, body)
+	return c.Do(t, path, http.MethodPost, body, opts...)
 }
 
 // application/x-www-form-urlencoded
-func (c *MockServer) PostForm(t *testing.T, path string, value url.Values) *Response {
-	return c.Post(t, path, "application/x-www-form-urlencoded", strings.NewReader(value.Encode()))
+func (c *MockServer) PostForm(t testing.TB, path string, value url.Values, opts ...RequestOption) *Response {
+	t.Helper()
+
+	return c.Post(t, path, "application/x-www-form-urlencoded", strings.NewReader(value.Encode()), opts...)
 }
 
 // application/json
-func (c *MockServer) PostJson(t *testing.T, path string, obj any) *Response {
+func (c *MockServer) PostJson(t testing.TB, path string, obj any, opts ...RequestOption) *Response {
+	t.Helper()
+
 	b, err := json.Marshal(obj)
 	require.NoError(t, err)
 
-	return c.Post(t, path, "application/json", bytes.NewReader(b))
+	return c.Post(t, path, "application/json", bytes.NewReader(b), opts...)
 }
 
-func (c *MockServer) PostString(t *testing.T, path string, contentType string, body string) *Response {
+func (c *MockServer) PostString(t testing.TB, path string, contentType string, body string, opts ...RequestOption) *Response {
+	t.Helper()
+
 	r := strings.NewReader(body)
-	resp := c.Post(t, path, contentType, r)
+	resp := c.Post(t, path, contentType, r, opts...)
 
 	return resp
 }
 
 // POST multipart/form-data
-func (c *MockServer) PostFormData(t *testing.T, path string, form *Multipart) *Response {
-	return c.FormData(t, path, http.MethodPost, form)
+func (c *MockServer) PostFormData(t testing.TB, path string, form *Multipart, opts ...RequestOption) *Response {
+	t.Helper()
+
+	return c.FormData(t, path, http.MethodPost, form, opts...)
 }
 
 // multipart/form-data
-func (c *MockServer) FormData(t *testing.T, path string, method string, form *Multipart) *Response {
+func (c *MockServer) FormData(t testing.TB, path string, method string, form *Multipart, opts ...RequestOption) *Response {
+	t.Helper()
+
 	body := form.Export()
 
-	c.Header.Add("Content-Type", form.ContentType())
+	opts = append([]RequestOption{WithHeader("Content-Type", form.ContentType())}, opts...)
 
-	return c.Do(t, path, method, body)
+	return c.Do(t, path, method, body, opts...)
 }
 
-func (c *MockServer) Do(t *testing.T, path string, method string, body io.Reader) *Response {
+// Send a request.
+// Default headers of the server are sent first, and options can overwrite them.
+func (c *MockServer) Do(t testing.TB, path string, method string, body io.Reader, opts ...RequestOption) *Response {
+	t.Helper()
+
 	r, err := http.NewRequest(method, c.URL(path), body)
 	require.NoError(t, err)
 
-	// insert headers
+	c.insertHeaders(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return c.send(t, r)
+}
+
+// Sends the request and wraps the response.
+func (c *MockServer) send(t testing.TB, r *http.Request) *Response {
+	t.Helper()
+
+	chain := &redirectChain{}
+	r = r.WithContext(context.WithValue(r.Context(), redirectChainKey{}, chain))
+
+	resp, err := c.Client.Do(r)
+	require.NoError(t, err)
+
+	response := NewResponse(resp)
+	response.redirects = chain.urls
+	response.dump = c.config.dump
+
+	if c.config.failOnLeak {
+		c.trackResponse(response)
+	}
+
+	return response
+}
+
+// Keeps the response to close it at cleanup.
+// Responses whose body has been read are already closed, so they are dropped.
+func (c *MockServer) trackResponse(response *Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	unread := c.responses[:0]
+	for _, r := range c.responses {
+		if !r.isBodyRead() {
+			unread = append(unread, r)
+		}
+	}
+	for i := len(unread); i < len(c.responses); i++ {
+		c.responses[i] = nil
+	}
+	c.responses = append(unread, response)
+}
+
+// Closes bodies of responses that have not been read, so that their connections are released.
+func (c *MockServer) closeResponses() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range c.responses {
+		r.closeUnread()
+	}
+	c.responses = nil
+}
+
+// Inserts the server's default headers into the request.
+func (c *MockServer) insertHeaders(r *http.Request) {
 	for key, values := range *c.Header {
 		for _, value := range values {
 			r.Header.Add(key, value)
 		}
 	}
+}
 
-	resp, err := client.Do(r)
-	require.NoError(t, err)
+// Creates the server that tracks requests and connections.
+func newUnstartedServer(handler http.Handler, config *serverConfig, tracker *connTracker) *httptest.Server {
+	handler = applyFaults(handler, config.faults, config.faultSeed)
+	if config.recorder != nil {
+		handler = config.recorder.Middleware(handler)
+	}
+
+	server := httptest.NewUnstartedServer(tracker.wrap(handler))
+	server.Config.ConnState = tracker.connState
+
+	return server
+}
+
+func newClient() *http.Client {
+	return &http.Client{
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		Jar:       newJar(),
+	}
+}
+
+// Creates the transport that sends HTTP/2 requests without TLS.
+func newH2CTransport() *http2.Transport {
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network string, addr string, _ *tls.Config) (net.Conn, error) {
+			dialer := &net.Dialer{}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+}
 
-	return NewResponse(resp)
+func newJar() http.CookieJar {
+	// cookiejar.New never returns an error
+	jar, _ := cookiejar.New(nil)
+	return jar
 }
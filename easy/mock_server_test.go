@@ -3,10 +3,12 @@ package easy_test
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/cateiru/go-http-easy-test/v2/easy"
@@ -337,3 +339,284 @@ func TestDo(t *testing.T) {
 		resp.Ok(t)
 	})
 }
+
+func TestCookieJar(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "12345", Path: "/"})
+	})
+	mux.HandleFunc("/form", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "token", Path: "/"})
+	})
+	mux.HandleFunc("/submit", func(w http.ResponseWriter, r *http.Request) {
+		csrf, err := r.Cookie("csrf")
+		if err != nil || r.PostFormValue("csrf") != csrf.Value {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	})
+
+	t.Run("session continuation", func(t *testing.T) {
+		s := easy.NewMockServer(mux)
+		defer s.Close()
+
+		s.Get(t, "/form").Status(t, http.StatusUnauthorized)
+
+		s.GetOK(t, "/login")
+		s.GetOK(t, "/form")
+
+		cookies := s.JarCookies(t, "/")
+		require.Len(t, cookies, 2)
+
+		resp := s.PostForm(t, "/submit", url.Values{"csrf": {"token"}})
+		resp.Ok(t)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		s := easy.NewMockServer(mux)
+		defer s.Close()
+
+		s.GetOK(t, "/login")
+		s.ClearJar()
+
+		require.Empty(t, s.JarCookies(t, "/"))
+		s.Get(t, "/form").Status(t, http.StatusUnauthorized)
+	})
+
+	t.Run("seed", func(t *testing.T) {
+		s := easy.NewMockServer(mux)
+		defer s.Close()
+
+		s.SetJarCookies(t, "/", []*http.Cookie{{Name: "session", Value: "aaaa"}})
+
+		s.GetOK(t, "/form")
+	})
+
+	t.Run("independent servers", func(t *testing.T) {
+		s1 := easy.NewMockServer(mux)
+		defer s1.Close()
+		s2 := easy.NewMockServer(mux)
+		defer s2.Close()
+
+		s1.GetOK(t, "/login")
+
+		require.Empty(t, s2.JarCookies(t, "/"))
+	})
+}
+
+func TestStartMockServer(t *testing.T) {
+	var s *easy.MockServer
+
+	t.Run("close on cleanup", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", Handler)
+
+		s = easy.StartMockServer(t, mux, easy.FailOnLeak())
+
+		s.GetOK(t, "/")
+	})
+
+	_, err := http.Get(s.URL("/"))
+	require.Error(t, err)
+
+	t.Run("TLS", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", Handler)
+
+		s := easy.StartMockTLSServer(t, mux)
+		require.Regexp(t, `https:\/\/.+`, s.Server.URL)
+	})
+}
+
+func TestFailOnLeak(t *testing.T) {
+	t.Run("in-flight request", func(t *testing.T) {
+		started := make(chan struct{})
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+		})
+
+		msg := expectFail(t, func(t testing.TB) {
+			s := easy.StartMockServer(t, mux, easy.FailOnLeak())
+
+			go http.Get(s.URL("/"))
+			<-started
+		})
+
+		require.Contains(t, msg, "1 request(s) in flight")
+	})
+
+	t.Run("idle connection", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", Handler)
+
+		client := &http.Client{Transport: &http.Transport{}}
+
+		msg := expectFail(t, func(t testing.TB) {
+			s := easy.StartMockServer(t, mux, easy.FailOnLeak())
+
+			resp, err := client.Get(s.URL("/"))
+			require.NoError(t, err)
+			io.ReadAll(resp.Body)
+			resp.Body.Close()
+		})
+
+		require.Contains(t, msg, "idle: 1")
+	})
+
+	t.Run("unread responses are closed", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", Handler)
+
+		s := easy.StartMockServer(t, mux, easy.FailOnLeak())
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			resp := s.Get(t, "/")
+			if i%2 == 0 {
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp.EqBody(t, "OK")
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestTLSServer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil {
+			w.WriteHeader(400)
+			return
+		}
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(r.Body)
+
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/cookie", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		w.Write([]byte(c.Value))
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "login", Path: "/"})
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, mux)
+
+		resp := s.GetOK(t, "/")
+		resp.EqBody(t, "OK")
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, mux)
+
+		resp := s.PostString(t, "/echo", "text/plain", "hello")
+		resp.Ok(t)
+		resp.EqBody(t, "hello")
+
+		resp = s.PostJson(t, "/echo", JsonData{Nya: "aaaa"})
+		resp.EqJson(t, JsonData{Nya: "aaaa"})
+	})
+
+	t.Run("Cookie", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, mux)
+
+		s.Cookie([]*http.Cookie{{Name: "session", Value: "12345"}})
+
+		resp := s.GetOK(t, "/cookie")
+		resp.EqBody(t, "12345")
+	})
+
+	t.Run("cookie jar", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, mux)
+
+		s.GetOK(t, "/login")
+
+		resp := s.GetOK(t, "/cookie")
+		resp.EqBody(t, "login")
+	})
+}
+
+func TestHTTP2(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, handler, easy.HTTP2())
+
+		resp := s.GetOK(t, "/")
+		resp.IsHTTP2(t)
+		resp.EqBody(t, "HTTP/2.0")
+	})
+
+	t.Run("h2c", func(t *testing.T) {
+		s := easy.StartMockServer(t, handler, easy.HTTP2())
+
+		resp := s.GetOK(t, "/")
+		resp.IsHTTP2(t)
+		resp.EqBody(t, "HTTP/2.0")
+
+		resp = s.PostJson(t, "/", JsonData{Nya: "aaaa"})
+		resp.EqProto(t, "HTTP/2.0")
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		s := easy.StartMockMTLSServer(t, handler, easy.HTTP2())
+
+		resp := s.GetOK(t, "/", easy.WithClientCert("alice"))
+		resp.IsHTTP2(t)
+	})
+
+	t.Run("HTTP/1.1 by default", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, handler)
+
+		resp := s.GetOK(t, "/")
+		resp.EqProto(t, "HTTP/1.1")
+		resp.EqBody(t, "HTTP/1.1")
+	})
+
+	t.Run("server push fallback", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The client does not accept server push, so the handler falls back.
+			result := "not pusher"
+			if p, ok := w.(http.Pusher); ok {
+				result = "pushed"
+				if err := p.Push("/style.css", nil); err != nil {
+					result = "fallback"
+				}
+			}
+			w.Write([]byte(result))
+		}), easy.HTTP2())
+
+		resp := s.GetOK(t, "/")
+		resp.EqBody(t, "fallback")
+	})
+
+	t.Run("FailOnLeak", func(t *testing.T) {
+		s := easy.StartMockServer(t, handler, easy.HTTP2(), easy.FailOnLeak())
+
+		s.GetOK(t, "/")
+		s.Get(t, "/")
+	})
+}
@@ -0,0 +1,290 @@
+package easy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory certificate authority that issues server and client certificates for tests.
+type CertAuthority struct {
+	Cert *x509.Certificate
+
+	key *ecdsa.PrivateKey
+}
+
+// Create a self-signed certificate authority.
+func NewCertAuthority() (*CertAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := certTemplate("go-http-easy-test CA")
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertAuthority{
+		Cert: cert,
+		key:  key,
+	}, nil
+}
+
+// Returns the pool that trusts only this authority.
+func (c *CertAuthority) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(c.Cert)
+	return pool
+}
+
+// Issue a server certificate for the hosts.
+// Hosts are IP addresses or DNS names.
+func (c *CertAuthority) IssueServerCert(hosts ...string) (tls.Certificate, error) {
+	template, err := certTemplate("go-http-easy-test server")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	return c.issue(template)
+}
+
+// Issue a client certificate.
+// The name is set to the common name of the subject.
+func (c *CertAuthority) IssueClientCert(name string) (tls.Certificate, error) {
+	template, err := certTemplate(name)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	return c.issue(template)
+}
+
+func (c *CertAuthority) issue(template *x509.Certificate) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.Cert, &key.PublicKey, c.key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der, c.Cert.Raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}, nil
+}
+
+func certTemplate(name string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(24 * time.Hour),
+	}, nil
+}
+
+// Start mock server with mutual TLS mode.
+// The server requires a client certificate issued by MockServer.CA,
+// and each request chooses the client certificate by WithClientCert.
+// Requests without WithClientCert present no certificate, so the handshake fails.
+//
+// Example:
+//
+//	s := easy.NewMockMTLSServer(mux)
+//	defer s.Close()
+//
+//	resp := s.Get(t, "/", easy.WithClientCert("alice"))
+func NewMockMTLSServer(handler http.Handler, opts ...ServerOption) *MockServer {
+	ca, err := NewCertAuthority()
+	if err != nil {
+		panic("easy: failed to create certificate authority: " + err.Error())
+	}
+	serverCert, err := ca.IssueServerCert("127.0.0.1", "::1", "example.com")
+	if err != nil {
+		panic("easy: failed to issue server certificate: " + err.Error())
+	}
+
+	config := newServerConfig(opts)
+	tracker := newConnTracker()
+	server := newUnstartedServer(handler, config, tracker)
+	server.EnableHTTP2 = config.http2
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.Pool(),
+	}
+	server.StartTLS()
+
+	base := server.Client().Transport.(*http.Transport)
+	base.TLSClientConfig.RootCAs = ca.Pool()
+
+	client := server.Client()
+	client.Transport = &clientCertTransport{
+		base:       base,
+		ca:         ca,
+		transports: map[string]*http.Transport{},
+	}
+	client.Jar = newJar()
+
+	s := &MockServer{
+		Server: server,
+		Client: client,
+		Header: &http.Header{},
+		CA:     ca,
+
+		redirectPolicy: FollowRedirects,
+		config:         config,
+		tracker:        tracker,
+	}
+	s.Client.CheckRedirect = s.checkRedirect
+
+	return s
+}
+
+// Start mock server with mutual TLS mode, and close it when the test finishes.
+// No need to call Close.
+func StartMockMTLSServer(t testing.TB, handler http.Handler, opts ...ServerOption) *MockServer {
+	t.Helper()
+
+	s := NewMockMTLSServer(handler, opts...)
+	t.Cleanup(func() { s.cleanup(t) })
+
+	return s
+}
+
+type clientCertKey struct{}
+
+// Present the client certificate of the name.
+// The certificate is issued by the CA of the mutual TLS server when it is first used,
+// and its common name is the name.
+func WithClientCert(name string) RequestOption {
+	return func(r *http.Request) {
+		*r = *r.WithContext(context.WithValue(r.Context(), clientCertKey{}, name))
+	}
+}
+
+// Sends requests with the transport of the client certificate chosen by WithClientCert.
+type clientCertTransport struct {
+	base *http.Transport
+	ca   *CertAuthority
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func (c *clientCertTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	name, _ := r.Context().Value(clientCertKey{}).(string)
+	if name == "" {
+		return c.base.RoundTrip(r)
+	}
+
+	transport, err := c.transport(name)
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(r)
+}
+
+// Returns the transport presenting the client certificate of the name.
+// Transports are separated by names so that connections are not shared between identities.
+func (c *clientCertTransport) transport(name string) (*http.Transport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if transport, ok := c.transports[name]; ok {
+		return transport, nil
+	}
+
+	cert, err := c.ca.IssueClientCert(name)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := c.base.Clone()
+	transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	c.transports[name] = transport
+
+	return transport, nil
+}
+
+func (c *clientCertTransport) CloseIdleConnections() {
+	c.base.CloseIdleConnections()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, transport := range c.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// Returns the common name of the client certificate of the request.
+// Returns empty string if the client did not present a certificate.
+func ClientCertName(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return ""
+	}
+	return r.TLS.PeerCertificates[0].Subject.CommonName
+}
+
+// Check the client presented the certificate of the name.
+// It can be called in handlers, since it does not stop the test.
+func EqClientCert(t testing.TB, r *http.Request, name string) bool {
+	t.Helper()
+
+	if !assert.NotNil(t, r.TLS, "request is not sent over TLS") {
+		return false
+	}
+	if !assert.NotEmpty(t, r.TLS.PeerCertificates, "client did not present a certificate") {
+		return false
+	}
+	return assert.Equal(t, name, ClientCertName(r), "common name of the client certificate")
+}
@@ -0,0 +1,99 @@
+package easy_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func clientCertHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(easy.ClientCertName(r)))
+}
+
+func TestMTLSServer(t *testing.T) {
+	t.Run("client identity per request", func(t *testing.T) {
+		s := easy.StartMockMTLSServer(t, http.HandlerFunc(clientCertHandler))
+
+		resp := s.GetOK(t, "/", easy.WithClientCert("alice"))
+		resp.EqBody(t, "alice")
+
+		resp = s.GetOK(t, "/", easy.WithClientCert("bob"))
+		resp.EqBody(t, "bob")
+
+		resp = s.PostString(t, "/", "text/plain", "hello", easy.WithClientCert("alice"))
+		resp.EqBody(t, "alice")
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		s := easy.StartMockMTLSServer(t, http.HandlerFunc(clientCertHandler))
+
+		resp, err := s.Client.Get(s.URL("/"))
+		if err == nil {
+			resp.Body.Close()
+		}
+		require.Error(t, err)
+	})
+
+	t.Run("certificate of other authority", func(t *testing.T) {
+		s := easy.StartMockMTLSServer(t, http.HandlerFunc(clientCertHandler))
+
+		other, err := easy.NewCertAuthority()
+		require.NoError(t, err)
+		cert, err := other.IssueClientCert("mallory")
+		require.NoError(t, err)
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      s.CA.Pool(),
+					Certificates: []tls.Certificate{cert},
+				},
+			},
+		}
+		defer client.CloseIdleConnections()
+
+		resp, err := client.Get(s.URL("/"))
+		if err == nil {
+			resp.Body.Close()
+		}
+		require.Error(t, err)
+	})
+
+	t.Run("peer certificates in handler", func(t *testing.T) {
+		var ca *easy.CertAuthority
+		s := easy.StartMockMTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !easy.EqClientCert(t, r, "alice") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			_, err := r.TLS.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:     ca.Pool(),
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			})
+			if err != nil {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		}))
+		ca = s.CA
+
+		s.GetOK(t, "/", easy.WithClientCert("alice"))
+	})
+}
+
+func TestEqClientCert(t *testing.T) {
+	var message string
+
+	s := easy.StartMockMTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message = expectFail(t, func(t testing.TB) {
+			easy.EqClientCert(t, r, "alice")
+		})
+	}))
+
+	s.GetOK(t, "/", easy.WithClientCert("bob"))
+	require.Contains(t, message, "common name of the client certificate")
+}
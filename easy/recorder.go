@@ -0,0 +1,303 @@
+package easy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Request received by the handler, recorded by Recorder.
+type RecordedRequest struct {
+	Method string
+	// Absolute URL of the request, built from the Host header.
+	URL    *url.URL
+	Proto  string
+	Header http.Header
+	Body   []byte
+	// Time when the request was received
+	Time time.Time
+
+	// Response written by the handler.
+	// It is nil until the handler writes the status.
+	Response *RecordedResponse
+}
+
+// Response written by the handler, recorded by Recorder.
+type RecordedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Time when the response was last written
+	Time time.Time
+}
+
+// Parse json body
+func (c *RecordedRequest) Json(v any) error {
+	return json.Unmarshal(c.Body, v)
+}
+
+// Records every request received by the handler.
+// It is safe for concurrent use.
+//
+// Example:
+//
+//	rec := easy.NewRecorder()
+//	s := easy.StartMockServer(t, mux, easy.Record(rec))
+//
+//	// ... run the code under test
+//
+//	rec.Match(http.MethodPost, "/events").Json(event).Received(t, 2)
+type Recorder struct {
+	mu       sync.Mutex
+	requests []*RecordedRequest
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Wraps the handler to record requests.
+// The body is read before the handler is called, and the handler can read it again.
+func (c *Recorder) Middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := time.Now()
+
+		body := []byte{}
+		if r.Body != nil {
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = b
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		u := *r.URL
+		u.Host = r.Host
+		u.Scheme = "http"
+		if r.TLS != nil {
+			u.Scheme = "https"
+		}
+
+		recorded := &RecordedRequest{
+			Method: r.Method,
+			URL:    &u,
+			Proto:  r.Proto,
+			Header: r.Header.Clone(),
+			Body:   body,
+			Time:   received,
+		}
+		c.add(recorded)
+
+		rw := &recordingWriter{ResponseWriter: w, recorder: c, recorded: recorded}
+		handler.ServeHTTP(rw.withInterfaces(), r)
+
+		// Sends the implicit status here, so that it is recorded before the client receives it.
+		if !rw.wroteHeader {
+			rw.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+// Returns recorded requests in the order they were received.
+func (c *Recorder) Requests() []*RecordedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	requests := make([]*RecordedRequest, len(c.requests))
+	for i, r := range c.requests {
+		requests[i] = r.clone()
+	}
+	return requests
+}
+
+// Remove all recorded requests.
+func (c *Recorder) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = nil
+}
+
+// Select recorded requests of the method and the path pattern.
+// The pattern is the same as StubServer.Expect.
+func (c *Recorder) Match(method string, pattern string) *RecordMatch {
+	return &RecordMatch{
+		requestMatcher: newRequestMatcher(method, pattern),
+		recorder:       c,
+	}
+}
+
+func (c *Recorder) add(r *RecordedRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, r)
+}
+
+// Returns a copy, so that it can be read while the handler writes the response.
+func (c *RecordedRequest) clone() *RecordedRequest {
+	r := *c
+	if c.Response != nil {
+		resp := *c.Response
+		r.Response = &resp
+	}
+	return &r
+}
+
+// ResponseWriter that records the response before sending it.
+type recordingWriter struct {
+	http.ResponseWriter
+
+	recorder    *Recorder
+	recorded    *RecordedRequest
+	wroteHeader bool
+}
+
+func (c *recordingWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+
+		c.recorder.mu.Lock()
+		c.recorded.Response = &RecordedResponse{
+			Status: status,
+			Header: c.Header().Clone(),
+			Body:   []byte{},
+			Time:   time.Now(),
+		}
+		c.recorder.mu.Unlock()
+	}
+
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *recordingWriter) Write(b []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+
+	c.recorder.mu.Lock()
+	c.recorded.Response.Body = append(c.recorded.Response.Body, b...)
+	c.recorded.Response.Time = time.Now()
+	c.recorder.mu.Unlock()
+
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *recordingWriter) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Used by http.ResponseController.
+func (c *recordingWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// Returns the writer that implements http.Pusher and http.Hijacker
+// only if the underlying writer does, so that recording doesn't change what the handler can do.
+// Responses written after hijacking are not recorded.
+func (c *recordingWriter) withInterfaces() http.ResponseWriter {
+	pusher, isPusher := c.ResponseWriter.(http.Pusher)
+	hijacker, isHijacker := c.ResponseWriter.(http.Hijacker)
+
+	switch {
+	case isPusher && isHijacker:
+		return &recordingPushHijacker{recordingWriter: c, Pusher: pusher, Hijacker: hijacker}
+	case isPusher:
+		return &recordingPusher{recordingWriter: c, Pusher: pusher}
+	case isHijacker:
+		return &recordingHijacker{recordingWriter: c, Hijacker: hijacker}
+	}
+	return c
+}
+
+type recordingPusher struct {
+	*recordingWriter
+	http.Pusher
+}
+
+type recordingHijacker struct {
+	*recordingWriter
+	http.Hijacker
+}
+
+type recordingPushHijacker struct {
+	*recordingWriter
+	http.Pusher
+	http.Hijacker
+}
+
+// Recorded requests selected by conditions.
+type RecordMatch struct {
+	requestMatcher
+
+	recorder *Recorder
+}
+
+// Select requests with the query param value
+func (c *RecordMatch) Query(key string, value string) *RecordMatch {
+	c.query.Add(key, value)
+	return c
+}
+
+// Select requests with the header value
+func (c *RecordMatch) Header(key string, value string) *RecordMatch {
+	c.header.Add(key, value)
+	return c
+}
+
+// Select requests with the body
+func (c *RecordMatch) Body(body string) *RecordMatch {
+	c.body = &body
+	return c
+}
+
+// Select requests with the json body.
+// It is compared semantically, and options such as JsonSubset can be used.
+func (c *RecordMatch) Json(obj any, opts ...JsonOption) *RecordMatch {
+	c.json = obj
+	c.jsonOpt = opts
+	return c
+}
+
+// Returns the selected requests.
+func (c *RecordMatch) Requests() []*RecordedRequest {
+	matched := []*RecordedRequest{}
+	for _, r := range c.recorder.Requests() {
+		req := &http.Request{Method: r.Method, URL: r.URL, Header: r.Header}
+		if c.matches(req, r.Body) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Check exactly n requests are selected
+func (c *RecordMatch) Received(t testing.TB, n int) {
+	t.Helper()
+
+	matched := c.Requests()
+	if len(matched) == n {
+		return
+	}
+
+	received := []string{}
+	for _, r := range c.recorder.Requests() {
+		received = append(received, fmt.Sprintf("%s %s", r.Method, r.URL.RequestURI()))
+	}
+	if len(received) == 0 {
+		received = append(received, "(none)")
+	}
+
+	t.Fatalf("expected %d request(s) of %s, got %d\nreceived:\n\t%s", n, c, len(matched), strings.Join(received, "\n\t"))
+}
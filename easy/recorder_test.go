@@ -0,0 +1,131 @@
+package easy_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("record requests", func(t *testing.T) {
+		rec := easy.NewRecorder()
+		s := easy.StartMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The handler can read the body again.
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		}), easy.Record(rec))
+
+		before := time.Now()
+		s.PostJson(t, "/events?source=web", JsonData{Nya: "aaaa"}).EqJson(t, JsonData{Nya: "aaaa"})
+		s.Get(t, "/", easy.WithHeader("X-Request-Id", "1")).Ok(t)
+
+		requests := rec.Requests()
+		require.Len(t, requests, 2)
+
+		require.Equal(t, http.MethodPost, requests[0].Method)
+		require.Equal(t, "/events?source=web", requests[0].URL.RequestURI())
+		require.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+		require.False(t, requests[0].Time.Before(before))
+
+		data := new(JsonData)
+		require.NoError(t, requests[0].Json(data))
+		require.Equal(t, "aaaa", data.Nya)
+
+		require.Equal(t, http.MethodGet, requests[1].Method)
+		require.Equal(t, "1", requests[1].Header.Get("X-Request-Id"))
+		require.Empty(t, requests[1].Body)
+
+		rec.Reset()
+		require.Empty(t, rec.Requests())
+	})
+
+	t.Run("concurrent requests", func(t *testing.T) {
+		rec := easy.NewRecorder()
+		s := easy.StartMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), easy.Record(rec))
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			i := i
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.PostJson(t, "/events", map[string]any{"id": i})
+			}()
+		}
+		wg.Wait()
+
+		rec.Match(http.MethodPost, "/events").Received(t, 10)
+		rec.Match(http.MethodPost, "/events").Json(map[string]any{"id": 3}).Received(t, 1)
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		rec := easy.NewRecorder()
+
+		m, err := easy.NewMock("/users/1", http.MethodDelete, "")
+		require.NoError(t, err)
+		m.Handler(rec.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP)
+
+		rec.Match(http.MethodDelete, "/users/{id}").Received(t, 1)
+	})
+}
+
+func TestRecorderKeepsInterfaces(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pusher := w.(http.Pusher)
+		_, hijacker := w.(http.Hijacker)
+		fmt.Fprintf(w, "pusher=%t hijacker=%t", pusher, hijacker)
+	})
+
+	t.Run("HTTP/2", func(t *testing.T) {
+		rec := easy.NewRecorder()
+		s := easy.StartMockTLSServer(t, handler, easy.HTTP2(), easy.Record(rec))
+
+		resp := s.GetOK(t, "/")
+		resp.IsHTTP2(t)
+		resp.EqBody(t, "pusher=true hijacker=false")
+
+		require.Equal(t, "pusher=true hijacker=false", string(rec.Requests()[0].Response.Body))
+	})
+
+	t.Run("HTTP/1.1", func(t *testing.T) {
+		rec := easy.NewRecorder()
+		s := easy.StartMockServer(t, handler, easy.Record(rec))
+
+		s.GetOK(t, "/").EqBody(t, "pusher=false hijacker=true")
+	})
+}
+
+func TestRecordMatch(t *testing.T) {
+	rec := easy.NewRecorder()
+	s := easy.StartMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), easy.Record(rec))
+
+	s.PostJson(t, "/events", map[string]any{"type": "click", "x": 1})
+	s.PostJson(t, "/events", map[string]any{"type": "click", "x": 2})
+	s.PostJson(t, "/events", map[string]any{"type": "view"})
+	s.PostString(t, "/logs?level=info", "text/plain", "hello", easy.WithHeader("X-Trace", "abc"))
+
+	t.Run("success", func(t *testing.T) {
+		rec.Match(http.MethodPost, "/events").Received(t, 3)
+		rec.Match(http.MethodPost, "/events").Json(map[string]any{"type": "click"}, easy.JsonSubset()).Received(t, 2)
+		rec.Match(http.MethodPost, "/logs").Query("level", "info").Header("X-Trace", "abc").Body("hello").Received(t, 1)
+		rec.Match(http.MethodGet, "/events").Received(t, 0)
+
+		require.Len(t, rec.Match(http.MethodPost, "/*").Requests(), 4)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		message := expectFail(t, func(t testing.TB) {
+			rec.Match(http.MethodPost, "/events").Json(map[string]any{"type": "click"}, easy.JsonSubset()).Received(t, 1)
+		})
+		require.Contains(t, message, "expected 1 request(s) of POST /events json, got 2")
+		require.Contains(t, message, fmt.Sprintf("received:\n\t%s\n\t%s\n\t%s\n\t%s",
+			"POST /events", "POST /events", "POST /events", "POST /logs?level=info"))
+	})
+}
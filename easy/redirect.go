@@ -0,0 +1,62 @@
+package easy
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Policy of following redirects of MockServer.
+type RedirectPolicy int
+
+const (
+	// Follow redirects up to 10 times, same as http.Client.
+	FollowRedirects RedirectPolicy = -1
+	// Don't follow redirects, returns the redirect response as is.
+	NoRedirects RedirectPolicy = 0
+)
+
+// Follow redirects up to n times.
+// If the server redirects more, returns the last redirect response as is.
+func RedirectLimit(n int) RedirectPolicy {
+	if n < 0 {
+		n = 0
+	}
+	return RedirectPolicy(n)
+}
+
+type redirectChainKey struct{}
+
+// URLs of redirects followed by a request.
+type redirectChain struct {
+	urls []*url.URL
+}
+
+// Set policy of following redirects.
+// Default is FollowRedirects.
+//
+// Example:
+//
+//	s.SetRedirectPolicy(easy.NoRedirects)
+//	resp := s.Get(t, "/login")
+//	resp.Status(t, http.StatusFound)
+//	resp.EqLocation(t, "/home")
+func (c *MockServer) SetRedirectPolicy(policy RedirectPolicy) {
+	c.redirectPolicy = policy
+}
+
+// Used as http.Client.CheckRedirect.
+func (c *MockServer) checkRedirect(req *http.Request, via []*http.Request) error {
+	if c.redirectPolicy == FollowRedirects {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+	} else if len(via) > int(c.redirectPolicy) {
+		return http.ErrUseLastResponse
+	}
+
+	if chain, ok := req.Context().Value(redirectChainKey{}).(*redirectChain); ok {
+		chain.urls = append(chain.urls, req.URL)
+	}
+	return nil
+}
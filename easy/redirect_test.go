@@ -0,0 +1,75 @@
+package easy_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func redirectMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/a", http.StatusFound)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/home", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/home", Handler)
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+
+	return mux
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	t.Run("FollowRedirects", func(t *testing.T) {
+		s := easy.NewMockServer(redirectMux())
+		defer s.Close()
+
+		resp := s.GetOK(t, "/login")
+		resp.EqBody(t, "OK")
+		resp.EqRedirectChain(t, "/a", "/b", "/home")
+		require.Len(t, resp.Redirects(), 3)
+	})
+
+	t.Run("NoRedirects", func(t *testing.T) {
+		s := easy.NewMockServer(redirectMux())
+		defer s.Close()
+
+		s.SetRedirectPolicy(easy.NoRedirects)
+
+		resp := s.Get(t, "/login")
+		resp.IsRedirect(t)
+		resp.Redirect(t, http.StatusFound, "/a")
+		resp.EqLocation(t, s.URL("/a"))
+		resp.EqRedirectChain(t)
+	})
+
+	t.Run("RedirectLimit", func(t *testing.T) {
+		s := easy.NewMockServer(redirectMux())
+		defer s.Close()
+
+		s.SetRedirectPolicy(easy.RedirectLimit(2))
+
+		resp := s.Get(t, "/login")
+		resp.Redirect(t, http.StatusTemporaryRedirect, "/home")
+		resp.EqRedirectChain(t, "/a", s.URL("/b"))
+	})
+
+	t.Run("redirect loop", func(t *testing.T) {
+		s := easy.NewMockServer(redirectMux())
+		defer s.Close()
+
+		r, err := http.NewRequest(http.MethodGet, s.URL("/loop"), nil)
+		require.NoError(t, err)
+
+		_, err = s.Client.Do(r)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,228 @@
+package easy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Chainable description of a request.
+// It can create a MockHandler or be sent to a MockServer,
+// so one request description works in both testing modes.
+//
+// Example:
+//
+//	b := easy.NewRequestBuilder().
+//		Method(http.MethodPost).
+//		Path("/users").
+//		Query("page", "1").
+//		Header("Authorization", "Bearer token").
+//		Json(user)
+//
+//	m, err := b.Mock()
+//	resp := b.Do(t, s)
+type RequestBuilder struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	cookies     []*http.Cookie
+	body        []byte
+	contentType string
+	remoteAddr  string
+	ctx         context.Context
+
+	err error
+}
+
+// Create a request builder.
+// Default is GET request to `/`.
+func NewRequestBuilder() *RequestBuilder {
+	return &RequestBuilder{
+		method: http.MethodGet,
+		path:   "/",
+		query:  url.Values{},
+		header: http.Header{},
+	}
+}
+
+// Set the request method
+func (c *RequestBuilder) Method(method string) *RequestBuilder {
+	c.method = method
+	return c
+}
+
+// Set the request path.
+// The path may contain query params, and they are merged with Query.
+func (c *RequestBuilder) Path(path string) *RequestBuilder {
+	c.path = path
+	return c
+}
+
+// Add a query param
+func (c *RequestBuilder) Query(key string, value string) *RequestBuilder {
+	c.query.Add(key, value)
+	return c
+}
+
+// Add a header
+func (c *RequestBuilder) Header(key string, value string) *RequestBuilder {
+	c.header.Add(key, value)
+	return c
+}
+
+// Including cookies in the request
+func (c *RequestBuilder) Cookie(cookies ...*http.Cookie) *RequestBuilder {
+	c.cookies = append(c.cookies, cookies...)
+	return c
+}
+
+// Set the body
+func (c *RequestBuilder) Body(body string) *RequestBuilder {
+	c.body = []byte(body)
+	return c
+}
+
+// Set the body from io.Reader.
+// The reader is read immediately so that the builder can be used many times.
+func (c *RequestBuilder) BodyReader(body io.Reader) *RequestBuilder {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		c.err = err
+	}
+	c.body = b
+	return c
+}
+
+// Set json body and `application/json` content-type
+func (c *RequestBuilder) Json(data any) *RequestBuilder {
+	b, err := json.Marshal(data)
+	if err != nil {
+		c.err = err
+	}
+	c.body = b
+	c.contentType = "application/json"
+	return c
+}
+
+// Set application/x-www-form-urlencoded body
+func (c *RequestBuilder) URLEncoded(data url.Values) *RequestBuilder {
+	c.body = []byte(data.Encode())
+	c.contentType = "application/x-www-form-urlencoded"
+	return c
+}
+
+// Set multipart/form-data body
+func (c *RequestBuilder) FormData(data *Multipart) *RequestBuilder {
+	c.body = data.Export().Bytes()
+	c.contentType = data.ContentType()
+	return c
+}
+
+// Set RemoteAddr.
+// It is only used by MockHandler, since MockServer is connected from a real address.
+func (c *RequestBuilder) RemoteAddr(addr string) *RequestBuilder {
+	c.remoteAddr = addr
+	return c
+}
+
+// Set the context of the request
+func (c *RequestBuilder) Context(ctx context.Context) *RequestBuilder {
+	c.ctx = ctx
+	return c
+}
+
+// Create mock objects from the request.
+func (c *RequestBuilder) Mock() (*MockHandler, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	path, err := c.fullPath()
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := NewMockReader(path, c.method, bytes.NewReader(c.body))
+	if err != nil {
+		return nil, err
+	}
+
+	c.applyHeader(m.R)
+	if len(c.cookies) > 0 {
+		m.Cookie(c.cookies)
+	}
+	if c.remoteAddr != "" {
+		m.SetAddr(c.remoteAddr)
+	}
+	if c.ctx != nil {
+		m.R = m.R.WithContext(c.ctx)
+	}
+
+	return m, nil
+}
+
+// Send the request to the mock server.
+func (c *RequestBuilder) Do(t testing.TB, s *MockServer) *Response {
+	t.Helper()
+
+	require.NoError(t, c.err)
+
+	path, err := c.fullPath()
+	require.NoError(t, err)
+
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r, err := http.NewRequestWithContext(ctx, c.method, s.URL(path), bytes.NewReader(c.body))
+	require.NoError(t, err)
+
+	s.insertHeaders(r)
+	c.applyHeader(r)
+	for _, cookie := range c.cookies {
+		r.AddCookie(cookie)
+	}
+
+	return s.send(t, r)
+}
+
+// Returns the path merged with query params.
+func (c *RequestBuilder) fullPath() (string, error) {
+	if len(c.query) == 0 {
+		return c.path, nil
+	}
+
+	u, err := url.Parse(c.path)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	for key, values := range c.query {
+		for _, value := range values {
+			q.Add(key, value)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Overwrites headers of the request with the builder's headers.
+func (c *RequestBuilder) applyHeader(r *http.Request) {
+	if c.contentType != "" && c.header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", c.contentType)
+	}
+
+	for key, values := range c.header {
+		r.Header[key] = append([]string{}, values...)
+	}
+}
@@ -0,0 +1,190 @@
+package easy_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+// Echoes the request as json.
+func echoRequestHandler(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		r.ParseForm()
+	}
+
+	cookies := map[string]string{}
+	for _, c := range r.Cookies() {
+		cookies[c.Name] = c.Value
+	}
+
+	ctxValue, _ := r.Context().Value(ctxKey{}).(string)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"query":       r.URL.Query(),
+		"contentType": r.Header.Get("Content-Type"),
+		"token":       r.Header.Get("X-Token"),
+		"cookies":     cookies,
+		"form":        r.PostForm,
+		"ctx":         ctxValue,
+	})
+}
+
+func TestRequestBuilder(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", echoRequestHandler)
+
+	s := easy.NewMockServer(mux)
+	defer s.Close()
+
+	cases := []struct {
+		Name     string
+		Builder  *easy.RequestBuilder
+		Expected map[string]any
+	}{
+		{
+			Name:    "default",
+			Builder: easy.NewRequestBuilder(),
+			Expected: map[string]any{
+				"method": "GET",
+				"path":   "/",
+			},
+		},
+		{
+			Name: "query and header",
+			Builder: easy.NewRequestBuilder().
+				Method(http.MethodDelete).
+				Path("/users?a=1").
+				Query("b", "2").
+				Header("X-Token", "token"),
+			Expected: map[string]any{
+				"method": "DELETE",
+				"path":   "/users",
+				"query":  map[string]any{"a": []string{"1"}, "b": []string{"2"}},
+				"token":  "token",
+			},
+		},
+		{
+			Name: "cookie",
+			Builder: easy.NewRequestBuilder().
+				Cookie(&http.Cookie{Name: "session", Value: "12345"}),
+			Expected: map[string]any{
+				"cookies": map[string]any{"session": "12345"},
+			},
+		},
+		{
+			Name: "url encoded",
+			Builder: easy.NewRequestBuilder().
+				Method(http.MethodPost).
+				URLEncoded(url.Values{"key": {"value"}}),
+			Expected: map[string]any{
+				"contentType": "application/x-www-form-urlencoded",
+				"form":        map[string]any{"key": []string{"value"}},
+			},
+		},
+		{
+			Name: "overwrite content-type",
+			Builder: easy.NewRequestBuilder().
+				Method(http.MethodPost).
+				Json(JsonData{Nya: "aaaa"}).
+				Header("Content-Type", "application/vnd.api+json"),
+			Expected: map[string]any{
+				"contentType": "application/vnd.api+json",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Run("MockHandler", func(t *testing.T) {
+				m, err := c.Builder.Mock()
+				require.NoError(t, err)
+
+				m.Handler(echoRequestHandler)
+
+				m.Ok(t)
+				m.ContainsJson(t, c.Expected)
+			})
+
+			t.Run("MockServer", func(t *testing.T) {
+				resp := c.Builder.Do(t, s)
+
+				resp.Ok(t)
+				resp.ContainsJson(t, c.Expected)
+			})
+		})
+	}
+}
+
+func TestRequestBuilderBody(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		obj := new(JsonData)
+		err := json.NewDecoder(r.Body).Decode(obj)
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		w.Write([]byte(obj.Nya))
+	})
+
+	s := easy.NewMockServer(mux)
+	defer s.Close()
+
+	t.Run("json", func(t *testing.T) {
+		b := easy.NewRequestBuilder().Method(http.MethodPost).Json(JsonData{Nya: "aaaa"})
+
+		// can be used many times
+		b.Do(t, s).EqBody(t, "aaaa")
+		b.Do(t, s).EqBody(t, "aaaa")
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		b := easy.NewRequestBuilder().Method(http.MethodPost).BodyReader(strings.NewReader(`{"nya":"bbbb"}`))
+
+		b.Do(t, s).EqBody(t, "bbbb")
+		b.Do(t, s).EqBody(t, "bbbb")
+	})
+}
+
+func TestRequestBuilderFormData(t *testing.T) {
+	form := easy.NewMultipart()
+	err := form.Insert("key", "value")
+	require.NoError(t, err)
+
+	m, err := easy.NewRequestBuilder().Method(http.MethodPost).FormData(form).Mock()
+	require.NoError(t, err)
+
+	m.Handler(echoRequestHandler)
+
+	m.EqJsonPath(t, "$.form.key[0]", "value")
+	m.ContainsHeader(t, "Content-Type", "application/json")
+}
+
+func TestRequestBuilderMockOnly(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	m, err := easy.NewRequestBuilder().RemoteAddr("203.0.113.0").Context(ctx).Mock()
+	require.NoError(t, err)
+
+	require.Equal(t, "203.0.113.0", m.R.RemoteAddr)
+	require.Equal(t, "value", m.R.Context().Value(ctxKey{}))
+
+	_, err = easy.NewRequestBuilder().Path("aaaaa").Mock()
+	require.Error(t, err)
+
+	_, err = easy.NewRequestBuilder().Json(func() {}).Mock()
+	require.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package easy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Conditions of requests, shared by StubServer and Recorder.
+type requestMatcher struct {
+	method  string
+	pattern string
+	query   url.Values
+	header  http.Header
+	body    *string
+	json    any
+	jsonOpt []JsonOption
+}
+
+func newRequestMatcher(method string, pattern string) requestMatcher {
+	return requestMatcher{
+		method:  method,
+		pattern: pattern,
+		query:   url.Values{},
+		header:  http.Header{},
+	}
+}
+
+// Describes the conditions, such as `GET /users/{id} query(page=1)`.
+func (c *requestMatcher) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s %s", c.method, c.pattern)
+
+	if len(c.query) > 0 {
+		fmt.Fprintf(b, " query(%s)", c.query.Encode())
+	}
+	for _, key := range sortedHeaderKeys(c.header) {
+		fmt.Fprintf(b, " header(%s: %s)", key, strings.Join(c.header[key], ", "))
+	}
+	if c.body != nil {
+		fmt.Fprintf(b, " body(%q)", *c.body)
+	}
+	if c.json != nil {
+		b.WriteString(" json")
+	}
+
+	return b.String()
+}
+
+func (c *requestMatcher) matches(r *http.Request, body []byte) bool {
+	if c.method != r.Method || !matchPathPattern(c.pattern, r.URL.Path) {
+		return false
+	}
+
+	query := r.URL.Query()
+	for key, values := range c.query {
+		for _, value := range values {
+			if !containsString(query[key], value) {
+				return false
+			}
+		}
+	}
+
+	for key, values := range c.header {
+		actual := headerValues(r.Header, key)
+		for _, value := range values {
+			if !containsString(actual, value) {
+				return false
+			}
+		}
+	}
+
+	if c.body != nil && *c.body != string(body) {
+		return false
+	}
+
+	if c.json != nil {
+		comparer, err := newJsonComparer(c.jsonOpt)
+		if err != nil {
+			return false
+		}
+		diffs, err := comparer.diff(c.json, body)
+		if err != nil || len(diffs) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Matches the path with the pattern such as `/users/{id}` or `/static/*`.
+func matchPathPattern(pattern string, path string) bool {
+	patterns := strings.Split(pattern, "/")
+	segments := strings.Split(path, "/")
+
+	for i, p := range patterns {
+		if p == "*" && i == len(patterns)-1 {
+			return len(segments) >= i
+		}
+		if i >= len(segments) {
+			return false
+		}
+
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if p != segments[i] {
+			return false
+		}
+	}
+
+	return len(patterns) == len(segments)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedHeaderKeys(header http.Header) []string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,36 @@
+package easy
+
+import (
+	"net/http"
+)
+
+// Option of one request sent to MockServer.
+// It does not change the server's default headers.
+type RequestOption func(r *http.Request)
+
+// Set the header.
+// Overwrites the default header of the same key.
+func WithHeader(key string, value string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// Set all values of the headers.
+// Overwrites the default headers of the same keys.
+func WithHeaders(header http.Header) RequestOption {
+	return func(r *http.Request) {
+		for key, values := range header {
+			r.Header[http.CanonicalHeaderKey(key)] = append([]string{}, values...)
+		}
+	}
+}
+
+// Including cookies in the request
+func WithCookie(cookies ...*http.Cookie) RequestOption {
+	return func(r *http.Request) {
+		for _, cookie := range cookies {
+			r.AddCookie(cookie)
+		}
+	}
+}
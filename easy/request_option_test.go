@@ -0,0 +1,88 @@
+package easy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns headers of the request as json.
+func headerEchoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{
+		"contentType": append([]string{}, r.Header.Values("Content-Type")...),
+		"token":       append([]string{}, r.Header.Values("X-Token")...),
+		"cookie":      append([]string{}, r.Header.Values("Cookie")...),
+	})
+}
+
+func TestRequestIndependent(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", headerEchoHandler)
+
+	s := easy.NewMockServer(mux)
+	defer s.Close()
+
+	resp := s.PostString(t, "/", "text/plain", "aaaa")
+	resp.EqJsonPath(t, "$.contentType", []string{"text/plain"})
+
+	resp = s.PostJson(t, "/", JsonData{Nya: "aaaa"})
+	resp.EqJsonPath(t, "$.contentType", []string{"application/json"})
+
+	resp = s.Get(t, "/")
+	resp.EqJsonPath(t, "$.contentType", []string{})
+
+	require.Empty(t, s.Header.Values("Content-Type"))
+}
+
+func TestWithHeader(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", headerEchoHandler)
+
+	s := easy.NewMockServer(mux)
+	defer s.Close()
+
+	s.Header.Set("X-Token", "default")
+
+	t.Run("default", func(t *testing.T) {
+		resp := s.Get(t, "/")
+		resp.EqJsonPath(t, "$.token", []string{"default"})
+	})
+
+	t.Run("overwrite default", func(t *testing.T) {
+		resp := s.Get(t, "/", easy.WithHeader("X-Token", "aaaa"))
+		resp.EqJsonPath(t, "$.token", []string{"aaaa"})
+
+		resp = s.Get(t, "/")
+		resp.EqJsonPath(t, "$.token", []string{"default"})
+	})
+
+	t.Run("WithHeaders", func(t *testing.T) {
+		resp := s.Get(t, "/", easy.WithHeaders(http.Header{"x-token": {"a", "b"}}))
+		resp.EqJsonPath(t, "$.token", []string{"a", "b"})
+	})
+
+	t.Run("overwrite content-type", func(t *testing.T) {
+		resp := s.PostString(t, "/", "text/plain", "aaaa", easy.WithHeader("Content-Type", "text/csv"))
+		resp.EqJsonPath(t, "$.contentType", []string{"text/csv"})
+	})
+}
+
+func TestWithCookie(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", headerEchoHandler)
+
+	s := easy.NewMockServer(mux)
+	defer s.Close()
+
+	s.Cookie([]*http.Cookie{{Name: "session", Value: "12345"}})
+
+	resp := s.Get(t, "/", easy.WithCookie(&http.Cookie{Name: "aaaa", Value: "value"}))
+	resp.EqJsonPath(t, "$.cookie", []string{"session=12345; aaaa=value"})
+
+	resp = s.Get(t, "/")
+	resp.EqJsonPath(t, "$.cookie", []string{"session=12345"})
+}
@@ -3,7 +3,10 @@ package easy
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,6 +14,15 @@ import (
 
 type Response struct {
 	Resp *http.Response
+
+	// guards body and bodyRead, closed by the server at cleanup
+	mu       sync.Mutex
+	body     []byte
+	bodyRead bool
+
+	redirects []*url.URL
+	// nil is DefaultDumpSettings
+	dump *DumpSettings
 }
 
 func NewResponse(resp *http.Response) *Response {
@@ -19,30 +31,41 @@ func NewResponse(resp *http.Response) *Response {
 	}
 }
 
-func (c *Response) Ok(t *testing.T) {
+func (c *Response) Ok(t testing.TB) {
+	t.Helper()
+
 	c.Status(t, 200)
 }
 
-func (c *Response) Status(t *testing.T, status int) {
-	require.Equal(t, c.Resp.StatusCode, status)
+func (c *Response) Status(t testing.TB, status int) {
+	t.Helper()
+
+	checkStatus(t, c, status)
 }
 
+// Returns response body.
+// The body is read only once, so it can be called any number of times.
 func (c *Response) Body() *bytes.Buffer {
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(c.Resp.Body)
+	return bytes.NewBuffer(c.bodyBytes())
+}
+
+func (c *Response) EqBody(t testing.TB, body string) {
+	t.Helper()
 
-	return buf
+	checkEqBody(t, c, body)
 }
 
-func (c *Response) EqBody(t *testing.T, body string) {
-	require.Equal(t, body, c.Body().String())
+func (c *Response) EqJson(t testing.TB, obj any, opts ...JsonOption) {
+	t.Helper()
+
+	checkEqJson(t, c, obj, opts)
 }
 
-func (c *Response) EqJson(t *testing.T, obj any) {
-	bytes, err := json.Marshal(obj)
-	require.NoError(t, err)
+// Check the response json contains at least the fields of obj
+func (c *Response) ContainsJson(t testing.TB, obj any, opts ...JsonOption) {
+	t.Helper()
 
-	require.Equal(t, c.Body().Bytes(), bytes)
+	checkEqJson(t, c, obj, append([]JsonOption{JsonSubset()}, opts...))
 }
 
 // Prase json body
@@ -55,3 +78,268 @@ func (c *Response) Json(v any) error {
 func (c *Response) SetCookies() []*http.Cookie {
 	return c.Resp.Cookies()
 }
+
+// Compare the json value at the path.
+// The path is JSONPath (`$.items[0].id`) or JSON Pointer (`/items/0/id`).
+// If JSONPath contains wildcards, the selected values are compared as an array.
+func (c *Response) EqJsonPath(t testing.TB, path string, value any, opts ...JsonOption) {
+	t.Helper()
+
+	checkEqJsonPath(t, c, path, value, opts)
+}
+
+// Check the type of the json value at the path
+func (c *Response) JsonPathType(t testing.TB, path string, typ JsonType) {
+	t.Helper()
+
+	checkJsonPathType(t, c, path, typ)
+}
+
+// Check the length of the array, object or string at the path.
+// If JSONPath contains wildcards, checks the number of selected values.
+func (c *Response) JsonPathLen(t testing.TB, path string, length int) {
+	t.Helper()
+
+	checkJsonPathLen(t, c, path, length)
+}
+
+// Check the json value exists at the path
+func (c *Response) HasJsonPath(t testing.TB, path string) {
+	t.Helper()
+
+	checkHasJsonPath(t, c, path)
+}
+
+// Check the json value does not exist at the path
+func (c *Response) NoJsonPath(t testing.TB, path string) {
+	t.Helper()
+
+	checkNoJsonPath(t, c, path)
+}
+
+// Compare status, selected headers and body with the golden file `testdata/<name>.golden`.
+// Run `EASY_UPDATE_SNAPSHOTS=1 go test` to create or update golden files.
+//
+// Example:
+//
+//	resp.Snapshot(t, "users/get", easy.SnapshotHeaders("Content-Type"), easy.SnapshotMaskUUID())
+func (c *Response) Snapshot(t testing.TB, name string, opts ...SnapshotOption) {
+	t.Helper()
+
+	checkSnapshot(t, c, name, opts)
+}
+
+// Returns soft assertions.
+// Failures don't stop the test, and are reported together by Done or at the end of the test.
+//
+// Example:
+//
+//	resp.Assert(t).Ok().EqHeader("Content-Type", "application/json").EqJson(obj).Done()
+func (c *Response) Assert(t testing.TB) *Assertion {
+	return newAssertion(t, c, true)
+}
+
+// Returns chainable assertions that stop the test at the first failure.
+func (c *Response) Require(t testing.TB) *Assertion {
+	return newAssertion(t, c, false)
+}
+
+// Check the first value of the header
+func (c *Response) EqHeader(t testing.TB, key string, value string) {
+	t.Helper()
+
+	checkEqHeader(t, c, key, value)
+}
+
+// Check all values of the header in order
+func (c *Response) EqHeaderValues(t testing.TB, key string, values ...string) {
+	t.Helper()
+
+	checkEqHeaderValues(t, c, key, values)
+}
+
+// Check the first value of the header contains substr
+func (c *Response) ContainsHeader(t testing.TB, key string, substr string) {
+	t.Helper()
+
+	checkContainsHeader(t, c, key, substr)
+}
+
+// Check the first value of the header matches the regular expression
+func (c *Response) MatchHeader(t testing.TB, key string, pattern string) {
+	t.Helper()
+
+	checkMatchHeader(t, c, key, pattern)
+}
+
+// Check the header is set
+func (c *Response) HasHeader(t testing.TB, key string) {
+	t.Helper()
+
+	checkHasHeader(t, c, key)
+}
+
+// Check the header is not set
+func (c *Response) NoHeader(t testing.TB, key string) {
+	t.Helper()
+
+	checkNoHeader(t, c, key)
+}
+
+// Check the response is a redirect (3xx)
+func (c *Response) IsRedirect(t testing.TB) {
+	t.Helper()
+	t = withDump(t, c)
+
+	status := c.Resp.StatusCode
+	require.True(t, status >= 300 && status < 400, "expected redirect status, got %d", status)
+}
+
+// Check the Location header.
+// Relative locations are resolved against the request URL,
+// so `/home` and `http://127.0.0.1:12345/home` are equal.
+func (c *Response) EqLocation(t testing.TB, location string) {
+	t.Helper()
+	t = withDump(t, c)
+
+	actual := c.Resp.Header.Get("Location")
+	require.NotEmpty(t, actual, "header Location is not set")
+
+	require.Equal(t, c.resolveURL(t, location), c.resolveURL(t, actual), "location")
+}
+
+// Check the response is a redirect of the status to the location
+func (c *Response) Redirect(t testing.TB, status int, location string) {
+	t.Helper()
+
+	c.Status(t, status)
+	c.EqLocation(t, location)
+}
+
+// Returns URLs of redirects followed by the request, in order.
+// Does not contain the URL of the first request.
+func (c *Response) Redirects() []*url.URL {
+	return c.redirects
+}
+
+// Check the redirects followed by the request.
+// Relative URLs are resolved against the request URL.
+//
+// Example:
+//
+//	// GET /a -> /b -> /c
+//	resp.EqRedirectChain(t, "/b", "/c")
+func (c *Response) EqRedirectChain(t testing.TB, urls ...string) {
+	t.Helper()
+	t = withDump(t, c)
+
+	expected := make([]string, len(urls))
+	for i, u := range urls {
+		expected[i] = c.resolveURL(t, u)
+	}
+
+	actual := make([]string, len(c.redirects))
+	for i, u := range c.redirects {
+		actual[i] = u.String()
+	}
+
+	require.Equal(t, expected, actual, "redirect chain")
+}
+
+// Check the protocol of the response, such as `HTTP/1.1` or `HTTP/2.0`
+func (c *Response) EqProto(t testing.TB, proto string) {
+	t.Helper()
+	t = withDump(t, c)
+
+	require.Equal(t, proto, c.Resp.Proto, "protocol")
+}
+
+// Check the response is sent over HTTP/2
+func (c *Response) IsHTTP2(t testing.TB) {
+	t.Helper()
+	t = withDump(t, c)
+
+	require.Equal(t, 2, c.Resp.ProtoMajor, "expected HTTP/2, got %s", c.Resp.Proto)
+}
+
+// Returns trailers of the response.
+// The body is read first, since trailers are received after the body.
+func (c *Response) Trailer() http.Header {
+	c.bodyBytes()
+
+	return c.Resp.Trailer
+}
+
+// Check the first value of the trailer
+func (c *Response) EqTrailer(t testing.TB, key string, value string) {
+	t.Helper()
+	t = withDump(t, c)
+
+	values := headerValues(c.Trailer(), key)
+	require.NotEmpty(t, values, "trailer %s is not set", key)
+
+	require.Equal(t, value, values[0], "trailer %s", key)
+}
+
+// Resolves the URL against the request URL.
+func (c *Response) resolveURL(t testing.TB, ref string) string {
+	t.Helper()
+
+	u, err := url.Parse(ref)
+	require.NoError(t, err)
+
+	if c.Resp.Request == nil || c.Resp.Request.URL == nil {
+		return u.String()
+	}
+	return c.Resp.Request.URL.ResolveReference(u).String()
+}
+
+func (c *Response) statusCode() int {
+	return c.Resp.StatusCode
+}
+
+func (c *Response) header() http.Header {
+	return c.Resp.Header
+}
+
+func (c *Response) request() *http.Request {
+	return c.Resp.Request
+}
+
+func (c *Response) dumpSettings() DumpSettings {
+	return dumpSettingsOf(c.dump)
+}
+
+func (c *Response) bodyBytes() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.bodyRead {
+		c.bodyRead = true
+
+		if c.Resp.Body != nil {
+			c.body, _ = io.ReadAll(c.Resp.Body)
+			c.Resp.Body.Close()
+		}
+	}
+
+	return c.body
+}
+
+// Whether the body has been read and closed.
+func (c *Response) isBodyRead() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.bodyRead
+}
+
+// Closes the body if it has not been read.
+func (c *Response) closeUnread() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.bodyRead && c.Resp.Body != nil {
+		c.Resp.Body.Close()
+	}
+}
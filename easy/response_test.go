@@ -3,6 +3,7 @@ package easy_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -101,3 +102,110 @@ func TestSetCookies(t *testing.T) {
 	require.Equal(t, cookies[0].Name, c.Name)
 	require.Equal(t, cookies[0].Value, c.Value)
 }
+
+func TestHeader(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 200,
+
+		Header: http.Header{
+			"Content-Type": []string{"application/json; charset=utf-8"},
+			"Vary":         []string{"Origin", "Accept-Encoding"},
+			"x-lower-case": []string{"aaaa"},
+		},
+	}
+	r := easy.NewResponse(resp)
+
+	t.Run("EqHeader", func(t *testing.T) {
+		r.EqHeader(t, "Content-Type", "application/json; charset=utf-8")
+		r.EqHeader(t, "content-type", "application/json; charset=utf-8")
+		r.EqHeader(t, "Vary", "Origin")
+	})
+
+	t.Run("EqHeaderValues", func(t *testing.T) {
+		r.EqHeaderValues(t, "vary", "Origin", "Accept-Encoding")
+	})
+
+	t.Run("ContainsHeader", func(t *testing.T) {
+		r.ContainsHeader(t, "Content-Type", "application/json")
+	})
+
+	t.Run("MatchHeader", func(t *testing.T) {
+		r.MatchHeader(t, "Content-Type", `^application/json;`)
+	})
+
+	t.Run("non canonical key", func(t *testing.T) {
+		r.EqHeader(t, "X-Lower-Case", "aaaa")
+		r.HasHeader(t, "x-LOWER-case")
+	})
+
+	t.Run("NoHeader", func(t *testing.T) {
+		r.NoHeader(t, "Location")
+	})
+}
+
+func TestBodyReadTwice(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 200,
+
+		Body: io.NopCloser(strings.NewReader("aaaa")),
+	}
+	r := easy.NewResponse(resp)
+
+	r.EqBody(t, "aaaa")
+	require.Equal(t, "aaaa", r.Body().String())
+}
+
+func TestProto(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("success", func(t *testing.T) {
+		s := easy.StartMockTLSServer(t, handler, easy.HTTP2())
+
+		resp := s.GetOK(t, "/")
+		resp.EqProto(t, "HTTP/2.0")
+		resp.IsHTTP2(t)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		s := easy.StartMockServer(t, handler)
+
+		resp := s.GetOK(t, "/")
+
+		message := expectFail(t, func(t testing.TB) {
+			resp.IsHTTP2(t)
+		})
+		require.Contains(t, message, "expected HTTP/2, got HTTP/1.1")
+
+		message = expectFail(t, func(t testing.TB) {
+			resp.EqProto(t, "HTTP/2.0")
+		})
+		require.Contains(t, message, "protocol")
+	})
+}
+
+func TestTrailer(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Write([]byte("body"))
+		w.Header().Set("X-Checksum", "abc")
+	})
+
+	for _, opts := range [][]easy.ServerOption{{}, {easy.HTTP2()}} {
+		opts := opts
+
+		t.Run(fmt.Sprintf("http2: %v", len(opts) > 0), func(t *testing.T) {
+			s := easy.StartMockServer(t, handler, opts...)
+
+			resp := s.GetOK(t, "/")
+			resp.EqTrailer(t, "X-Checksum", "abc")
+			resp.EqTrailer(t, "x-checksum", "abc")
+			resp.EqBody(t, "body")
+			require.Equal(t, "abc", resp.Trailer().Get("X-Checksum"))
+
+			message := expectFail(t, func(t testing.TB) {
+				resp.EqTrailer(t, "X-Other", "abc")
+			})
+			require.Contains(t, message, "trailer X-Other is not set")
+		})
+	}
+}
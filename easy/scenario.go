@@ -0,0 +1,359 @@
+package easy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// Table of requests and expected responses.
+// It is loaded as YAML if the file extension is `.yaml` or `.yml`, otherwise as JSON.
+//
+// Example:
+//
+//	cases:
+//	  - name: create user
+//	    request:
+//	      method: POST
+//	      path: /users
+//	      json: {name: cateiru}
+//	    expect:
+//	      status: 201
+//	      headers: {Content-Type: application/json}
+//	      jsonPath: {$.name: cateiru}
+type Scenario struct {
+	Cases []*ScenarioCase `json:"cases" yaml:"cases"`
+}
+
+type ScenarioCase struct {
+	// Name of the subtest. Default is the method and the path.
+	Name    string          `json:"name" yaml:"name"`
+	Request ScenarioRequest `json:"request" yaml:"request"`
+	Expect  ScenarioExpect  `json:"expect" yaml:"expect"`
+}
+
+// Only one of Body, Json, Form and Multipart can be set.
+type ScenarioRequest struct {
+	// Default is GET
+	Method  string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Path    string            `json:"path" yaml:"path"`
+	Query   map[string]string `json:"query,omitempty" yaml:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty" yaml:"cookies,omitempty"`
+
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+	// Sent as application/json
+	Json any `json:"json,omitempty" yaml:"json,omitempty"`
+	// Sent as application/x-www-form-urlencoded
+	Form      map[string]string  `json:"form,omitempty" yaml:"form,omitempty"`
+	Multipart *ScenarioMultipart `json:"multipart,omitempty" yaml:"multipart,omitempty"`
+}
+
+// multipart/form-data body.
+type ScenarioMultipart struct {
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Paths of the files, relative to the scenario file
+	Files map[string]string `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// Expected response. Empty fields are not checked.
+type ScenarioExpect struct {
+	Status       int               `json:"status,omitempty" yaml:"status,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body         *string           `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyContains string            `json:"bodyContains,omitempty" yaml:"bodyContains,omitempty"`
+	Json         any               `json:"json,omitempty" yaml:"json,omitempty"`
+	// Fields that exist only in the response are ignored
+	ContainsJson any `json:"containsJson,omitempty" yaml:"containsJson,omitempty"`
+	// Paths ignored by Json and ContainsJson
+	JsonIgnore []string       `json:"jsonIgnore,omitempty" yaml:"jsonIgnore,omitempty"`
+	JsonPath   map[string]any `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+	// Values of Set-Cookie
+	Cookies map[string]string `json:"cookies,omitempty" yaml:"cookies,omitempty"`
+}
+
+// Load the scenario file.
+// Unknown fields are errors, to find typos.
+func LoadScenario(path string) (*Scenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario := &Scenario{}
+	if isYamlFile(path) {
+		decoder := yaml.NewDecoder(bytes.NewReader(b))
+		decoder.KnownFields(true)
+		err = decoder.Decode(scenario)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(scenario)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+
+	return scenario, nil
+}
+
+// Run cases of the scenario file with MockHandler, as subtests.
+//
+// Example:
+//
+//	easy.RunScenario(t, handler, "testdata/users.yaml")
+func RunScenario(t *testing.T, handler http.Handler, path string) {
+	t.Helper()
+
+	runScenario(t, path, func(t testing.TB, c *ScenarioCase, body io.Reader, header http.Header) Result {
+		t.Helper()
+
+		m, err := NewMockReader(c.Request.url(), c.Request.method(), body)
+		require.NoError(t, err)
+		for key, values := range header {
+			m.R.Header[key] = values
+		}
+
+		m.Handler(handler.ServeHTTP)
+		return m
+	})
+}
+
+// Run cases of the scenario file against the server, as subtests.
+// Default headers of the server are sent, and cookies are kept between cases.
+//
+// Example:
+//
+//	s := easy.StartMockServer(t, handler)
+//	s.RunScenario(t, "testdata/users.yaml")
+func (c *MockServer) RunScenario(t *testing.T, path string) {
+	t.Helper()
+
+	runScenario(t, path, func(t testing.TB, sc *ScenarioCase, body io.Reader, header http.Header) Result {
+		t.Helper()
+
+		r, err := http.NewRequest(sc.Request.method(), c.URL(sc.Request.url()), body)
+		require.NoError(t, err)
+
+		c.insertHeaders(r)
+		for key, values := range header {
+			r.Header[key] = values
+		}
+
+		return c.send(t, r)
+	})
+}
+
+func runScenario(t *testing.T, path string, send func(testing.TB, *ScenarioCase, io.Reader, http.Header) Result) {
+	t.Helper()
+
+	scenario, err := LoadScenario(path)
+	require.NoError(t, err)
+
+	dir := filepath.Dir(path)
+	for _, c := range scenario.Cases {
+		c := c
+
+		t.Run(c.testName(), func(t *testing.T) {
+			body, header, err := c.Request.build(dir)
+			require.NoError(t, err)
+
+			r := send(t, c, body, header)
+			c.Expect.check(t, r)
+		})
+	}
+}
+
+func (c *ScenarioCase) testName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Request.method() + " " + c.Request.Path
+}
+
+func (c *ScenarioRequest) method() string {
+	if c.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(c.Method)
+}
+
+// Returns the path with the query params.
+func (c *ScenarioRequest) url() string {
+	if len(c.Query) == 0 {
+		return c.Path
+	}
+
+	query := url.Values{}
+	for key, value := range c.Query {
+		query.Set(key, value)
+	}
+
+	separator := "?"
+	if strings.Contains(c.Path, "?") {
+		separator = "&"
+	}
+	return c.Path + separator + query.Encode()
+}
+
+// Creates the body and headers of the request.
+func (c *ScenarioRequest) build(dir string) (io.Reader, http.Header, error) {
+	header := http.Header{}
+	for key, value := range c.Headers {
+		header.Set(key, value)
+	}
+
+	if len(c.Cookies) > 0 {
+		cookies := make([]string, 0, len(c.Cookies))
+		for _, name := range sortedStringKeys(c.Cookies) {
+			cookies = append(cookies, (&http.Cookie{Name: name, Value: c.Cookies[name]}).String())
+		}
+		header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+
+	bodies := 0
+	for _, set := range []bool{c.Body != "", c.Json != nil, c.Form != nil, c.Multipart != nil} {
+		if set {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return nil, nil, errors.New("only one of body, json, form and multipart can be set")
+	}
+
+	switch {
+	case c.Json != nil:
+		b, err := json.Marshal(c.Json)
+		if err != nil {
+			return nil, nil, err
+		}
+		setDefaultHeader(header, "Content-Type", "application/json")
+		return bytes.NewReader(b), header, nil
+	case c.Form != nil:
+		form := url.Values{}
+		for key, value := range c.Form {
+			form.Set(key, value)
+		}
+		setDefaultHeader(header, "Content-Type", "application/x-www-form-urlencoded")
+		return strings.NewReader(form.Encode()), header, nil
+	case c.Multipart != nil:
+		m, err := c.Multipart.build(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.Set("Content-Type", m.ContentType())
+		return m.Export(), header, nil
+	}
+
+	return strings.NewReader(c.Body), header, nil
+}
+
+func (c *ScenarioMultipart) build(dir string) (*Multipart, error) {
+	m := NewMultipart()
+
+	for _, key := range sortedStringKeys(c.Fields) {
+		if err := m.Insert(key, c.Fields[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range sortedStringKeys(c.Files) {
+		path := c.Files[key]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = m.InsertFile(key, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (c *ScenarioExpect) check(t testing.TB, r Result) {
+	t.Helper()
+
+	if c.Status != 0 {
+		checkStatus(t, r, c.Status)
+	}
+
+	for _, key := range sortedStringKeys(c.Headers) {
+		checkEqHeader(t, r, key, c.Headers[key])
+	}
+
+	if c.Body != nil {
+		checkEqBody(t, r, *c.Body)
+	}
+	if c.BodyContains != "" {
+		require.Contains(t, string(r.bodyBytes()), c.BodyContains)
+	}
+
+	opts := []JsonOption{}
+	if len(c.JsonIgnore) > 0 {
+		opts = append(opts, JsonIgnore(c.JsonIgnore...))
+	}
+	if c.Json != nil {
+		checkEqJson(t, r, c.Json, opts)
+	}
+	if c.ContainsJson != nil {
+		checkEqJson(t, r, c.ContainsJson, append([]JsonOption{JsonSubset()}, opts...))
+	}
+
+	paths := make([]string, 0, len(c.JsonPath))
+	for path := range c.JsonPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		checkEqJsonPath(t, r, path, c.JsonPath[path], nil)
+	}
+
+	if len(c.Cookies) > 0 {
+		t := withDump(t, r)
+
+		cookies := map[string]string{}
+		for _, cookie := range (&http.Response{Header: r.header()}).Cookies() {
+			cookies[cookie.Name] = cookie.Value
+		}
+		for _, name := range sortedStringKeys(c.Cookies) {
+			value, ok := cookies[name]
+			require.True(t, ok, "cookie %s is not set", name)
+			require.Equal(t, c.Cookies[name], value, "cookie %s", name)
+		}
+	}
+}
+
+// Sets the header if it is not set.
+func setDefaultHeader(header http.Header, key string, value string) {
+	if header.Get(key) == "" {
+		header.Set(key, value)
+	}
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
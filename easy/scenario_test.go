@@ -0,0 +1,91 @@
+package easy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func scenarioHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		user := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user["id"] = 1
+		user["createdAt"] = "2022-10-01T00:00:00Z"
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(user)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: r.FormValue("name"), Path: "/"})
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(c.Value))
+	})
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"q": r.URL.Query().Get("q")})
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("image")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"title":    r.FormValue("title"),
+			"filename": header.Filename,
+			"size":     header.Size,
+		})
+	})
+	return mux
+}
+
+func TestLoadScenario(t *testing.T) {
+	scenario, err := easy.LoadScenario("testdata/scenario/users.yaml")
+	require.NoError(t, err)
+
+	require.Len(t, scenario.Cases, 6)
+	require.Equal(t, "create user", scenario.Cases[0].Name)
+	require.Equal(t, http.StatusCreated, scenario.Cases[0].Expect.Status)
+	require.Equal(t, map[string]string{"image": "../../test_image.png"}, scenario.Cases[5].Request.Multipart.Files)
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "scenario.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("cases:\n  - name: a\n    expected:\n      status: 200\n"), 0o644))
+
+		_, err := easy.LoadScenario(path)
+		require.ErrorContains(t, err, "field expected not found")
+	})
+}
+
+func TestRunScenario(t *testing.T) {
+	for _, path := range []string{"testdata/scenario/users.yaml", "testdata/scenario/users.json"} {
+		path := path
+
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			t.Run("MockHandler", func(t *testing.T) {
+				easy.RunScenario(t, scenarioHandler(), path)
+			})
+
+			t.Run("MockServer", func(t *testing.T) {
+				s := easy.StartMockServer(t, scenarioHandler())
+				s.RunScenario(t, path)
+			})
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package easy
+
+// Option of MockServer.
+type ServerOption func(*serverConfig)
+
+type serverConfig struct {
+	failOnLeak bool
+	http2      bool
+	recorder   *Recorder
+	faults     []faultWrapper
+	faultSeed  int64
+	dump       *DumpSettings
+}
+
+func newServerConfig(opts []ServerOption) *serverConfig {
+	config := &serverConfig{
+		faultSeed: defaultFaultSeed,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// Fail the test if requests are still in flight or connections are left open when the server is closed.
+// Connections of MockServer.Client are closed before checking.
+// It works with StartMockServer and StartMockTLSServer, which know the test.
+func FailOnLeak() ServerOption {
+	return func(c *serverConfig) {
+		c.failOnLeak = true
+	}
+}
+
+// Enable HTTP/2.
+// The TLS server negotiates HTTP/2 by ALPN, and the server without TLS speaks h2c (HTTP/2 over cleartext).
+// MockServer.Client is configured to use HTTP/2, so every request is sent over HTTP/2.
+func HTTP2() ServerOption {
+	return func(c *serverConfig) {
+		c.http2 = true
+	}
+}
+
+// Record every request received by the server.
+func Record(rec *Recorder) ServerOption {
+	return func(c *serverConfig) {
+		c.recorder = rec
+	}
+}
+
+// Set the dump logged when an assertion of the server's responses fails, instead of the package defaults.
+//
+// Example:
+//
+//	settings := easy.DefaultDumpSettings()
+//	settings.Level = easy.DumpHeaders
+//	s := easy.StartMockServer(t, handler, easy.Dump(settings))
+func Dump(settings DumpSettings) ServerOption {
+	return func(c *serverConfig) {
+		settings.RedactHeaders = append([]string{}, settings.RedactHeaders...)
+		c.dump = &settings
+	}
+}
@@ -0,0 +1,225 @@
+package easy
+
+import (
+	"flag"
+	"fmt"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Regenerate golden files instead of comparing them.
+// Default is true when the environment variable `EASY_UPDATE_SNAPSHOTS` is set,
+// e.g. `EASY_UPDATE_SNAPSHOTS=1 go test ./...`.
+var UpdateSnapshots = os.Getenv("EASY_UPDATE_SNAPSHOTS") != ""
+
+// Whether golden files and cassettes are updated.
+// The `-update` flag is also respected if the test binary defines it.
+func shouldUpdate() bool {
+	if UpdateSnapshots {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if v, ok := getter.Get().(bool); ok {
+				return v
+			}
+		}
+	}
+	return false
+}
+
+// Directory where golden files are stored.
+const snapshotDir = "testdata"
+
+var (
+	uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	// RFC 3339 (e.g. 2022-10-01T12:00:00.123Z) and HTTP date (e.g. Sat, 01 Oct 2022 12:00:00 GMT)
+	datePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?|[A-Z][a-z]{2}, \d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2} GMT`)
+)
+
+// Option of snapshot.
+type SnapshotOption func(*snapshotConfig)
+
+type snapshotConfig struct {
+	headers   []string
+	masks     []snapshotMask
+	jsonMasks [][]pathSegment
+
+	err error
+}
+
+type snapshotMask struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// Include the headers in the snapshot.
+// Headers are not included by default, since many of them change on every run.
+func SnapshotHeaders(keys ...string) SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.headers = append(c.headers, keys...)
+	}
+}
+
+// Replace strings matched by the regular expression in headers and body.
+//
+// Example:
+//
+//	SnapshotMask(`token=[a-z0-9]+`, "token=<token>")
+func SnapshotMask(pattern string, replacement string) SnapshotOption {
+	return func(c *snapshotConfig) {
+		reg, err := regexp.Compile(pattern)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.masks = append(c.masks, snapshotMask{pattern: reg, replacement: replacement})
+	}
+}
+
+// Replace UUIDs with `<uuid>`.
+func SnapshotMaskUUID() SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.masks = append(c.masks, snapshotMask{pattern: uuidPattern, replacement: "<uuid>"})
+	}
+}
+
+// Replace RFC 3339 and HTTP dates with `<date>`.
+func SnapshotMaskDate() SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.masks = append(c.masks, snapshotMask{pattern: datePattern, replacement: "<date>"})
+	}
+}
+
+// Replace json values at the paths with `<masked>`.
+// Paths are JSONPath or JSON Pointer, and JSONPath can use wildcards.
+//
+// Example:
+//
+//	SnapshotMaskJson("$.id", "$.items[*].createdAt")
+func SnapshotMaskJson(paths ...string) SnapshotOption {
+	return func(c *snapshotConfig) {
+		for _, path := range paths {
+			segments, err := parsePath(path)
+			if err != nil {
+				c.err = err
+				return
+			}
+			c.jsonMasks = append(c.jsonMasks, segments)
+		}
+	}
+}
+
+func checkSnapshot(t testing.TB, r Result, name string, opts []SnapshotOption) {
+	t.Helper()
+	t = withDump(t, r)
+
+	config := &snapshotConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	require.NoError(t, config.err)
+
+	actual := config.render(r)
+	path := filepath.Join(snapshotDir, filepath.FromSlash(name)+".golden")
+
+	if shouldUpdate() {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(actual), 0o644)
+		require.NoError(t, err)
+
+		t.Logf("updated snapshot %s", path)
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		require.Fail(t, fmt.Sprintf("snapshot %s does not exist, set EASY_UPDATE_SNAPSHOTS=1 to create it", path))
+	}
+	require.NoError(t, err)
+
+	require.Equal(t, string(expected), actual, "snapshot %s", path)
+}
+
+// Renders status, selected headers and body.
+//
+// Format:
+//
+//	Status: 200
+//	Content-Type: application/json
+//
+//	{
+//	  "key": "value"
+//	}
+func (c *snapshotConfig) render(r Result) string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "Status: %d\n", r.statusCode())
+
+	keys := make([]string, len(c.headers))
+	for i, key := range c.headers {
+		keys[i] = textproto.CanonicalMIMEHeaderKey(key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range headerValues(r.header(), key) {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(c.renderBody(r.bodyBytes()))
+
+	return c.mask(b.String())
+}
+
+// Pretty-prints json body, otherwise returns body as is.
+func (c *snapshotConfig) renderBody(body []byte) string {
+	doc, err := decodeJson(body)
+	if err != nil {
+		return string(body)
+	}
+
+	for _, segments := range c.jsonMasks {
+		for _, m := range selectJson(doc, segments) {
+			doc = replaceJson(doc, m.path, "<masked>")
+		}
+	}
+
+	pretty, err := encodePrettyJson(doc)
+	if err != nil {
+		return string(body)
+	}
+
+	return pretty
+}
+
+func (c *snapshotConfig) mask(s string) string {
+	for _, m := range c.masks {
+		s = m.pattern.ReplaceAllString(s, m.replacement)
+	}
+	return s
+}
+
+// Replaces the value at the concrete path.
+func replaceJson(doc any, path []pathSegment, value any) any {
+	if len(path) == 0 {
+		return value
+	}
+
+	switch v := doc.(type) {
+	case map[string]any:
+		v[path[0].key] = replaceJson(v[path[0].key], path[1:], value)
+	case []any:
+		v[path[0].index] = replaceJson(v[path[0].index], path[1:], value)
+	}
+	return doc
+}
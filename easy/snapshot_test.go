@@ -0,0 +1,87 @@
+package easy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-Id", "0c7f1d7a-3d2f-4f86-9c59-93b5e5a3e2b4")
+	w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"token":     time.Now().UnixNano(),
+		"name":      "cateiru",
+		"createdAt": time.Now().Format(time.RFC3339Nano),
+		"items":     []any{map[string]any{"id": 1, "tag": "<a>"}},
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Run("MockHandler", func(t *testing.T) {
+		m, err := easy.NewMock("/", http.MethodGet, "")
+		require.NoError(t, err)
+
+		m.Handler(snapshotHandler)
+
+		m.Snapshot(t, "snapshot/json",
+			easy.SnapshotHeaders("content-type", "X-Request-Id", "Date"),
+			easy.SnapshotMaskUUID(),
+			easy.SnapshotMaskDate(),
+			easy.SnapshotMaskJson("$.token"),
+		)
+	})
+
+	t.Run("Response", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", snapshotHandler)
+
+		s := easy.NewMockServer(mux)
+		defer s.Close()
+
+		resp := s.Get(t, "/")
+
+		resp.Snapshot(t, "snapshot/json",
+			easy.SnapshotHeaders("Content-Type", "X-Request-Id", "Date"),
+			easy.SnapshotMaskUUID(),
+			easy.SnapshotMaskDate(),
+			easy.SnapshotMaskJson("$.token"),
+		)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		m, err := easy.NewMock("/", http.MethodGet, "")
+		require.NoError(t, err)
+
+		m.Handler(Handler)
+
+		m.Snapshot(t, "snapshot/text", easy.SnapshotMask(`O`, "0"))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		easy.UpdateSnapshots = true
+		defer func() { easy.UpdateSnapshots = false }()
+
+		path := filepath.Join("testdata", "snapshot", "updated.golden")
+		defer os.Remove(path)
+
+		m, err := easy.NewMock("/", http.MethodGet, "")
+		require.NoError(t, err)
+
+		m.Handler(Handler)
+
+		m.Snapshot(t, "snapshot/updated")
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Contains(t, string(b), "OK")
+	})
+}
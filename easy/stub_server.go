@@ -0,0 +1,273 @@
+package easy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Stub of an upstream server.
+// Tests declare expected requests and their responses,
+// and Verify checks every expected request was received the expected number of times.
+//
+// Example:
+//
+//	s := easy.StartStubServer(t)
+//	s.Expect(http.MethodGet, "/users/{id}").
+//		Header("Authorization", "Bearer token").
+//		ReplyJson(http.StatusOK, user)
+//
+//	client := api.NewClient(s.URL(""))
+type StubServer struct {
+	*MockServer
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+// Expected request of StubServer and its response.
+// Declare it before the request is sent.
+type Expectation struct {
+	requestMatcher
+
+	mu *sync.Mutex
+
+	// Expected number of calls. -1 means any times.
+	times int
+	calls int
+
+	status      int
+	replyHeader http.Header
+	replyBody   []byte
+
+	err error
+}
+
+// Start stub server.
+// Call Verify and Close at the end of the test.
+func NewStubServer(opts ...ServerOption) *StubServer {
+	s := &StubServer{}
+	s.MockServer = NewMockServer(http.HandlerFunc(s.serveHTTP), opts...)
+
+	return s
+}
+
+// Start stub server with TLS mode.
+// Call Verify and Close at the end of the test.
+func NewStubTLSServer(opts ...ServerOption) *StubServer {
+	s := &StubServer{}
+	s.MockServer = NewMockTLSServer(http.HandlerFunc(s.serveHTTP), opts...)
+
+	return s
+}
+
+// Start stub server, and verify the expectations and close it when the test finishes.
+func StartStubServer(t testing.TB, opts ...ServerOption) *StubServer {
+	t.Helper()
+
+	s := NewStubServer(opts...)
+	t.Cleanup(func() {
+		s.Verify(t)
+		s.cleanup(t)
+	})
+
+	return s
+}
+
+// Start stub server with TLS mode, and verify the expectations and close it when the test finishes.
+func StartStubTLSServer(t testing.TB, opts ...ServerOption) *StubServer {
+	t.Helper()
+
+	s := NewStubTLSServer(opts...)
+	t.Cleanup(func() {
+		s.Verify(t)
+		s.cleanup(t)
+	})
+
+	return s
+}
+
+// Expect a request.
+// By default it is expected once, and replies 200 with empty body.
+//
+// The path pattern matches the path of the request.
+// `{name}` matches one segment, and `*` at the end matches the rest of the path.
+//
+// Example:
+//
+//	s.Expect(http.MethodGet, "/users/{id}")
+//	s.Expect(http.MethodGet, "/static/*")
+func (c *StubServer) Expect(method string, pattern string) *Expectation {
+	e := &Expectation{
+		requestMatcher: newRequestMatcher(method, pattern),
+
+		mu:          &c.mu,
+		times:       1,
+		status:      http.StatusOK,
+		replyHeader: http.Header{},
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expectations = append(c.expectations, e)
+
+	return e
+}
+
+// Check every expectation was called the expected number of times,
+// and no unexpected requests were received.
+func (c *StubServer) Verify(t testing.TB) {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failures := []string{}
+	for _, e := range c.expectations {
+		if e.err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", e, e.err))
+		}
+		if e.times >= 0 && e.calls != e.times {
+			failures = append(failures, fmt.Sprintf("%s: expected %d call(s), got %d", e, e.times, e.calls))
+		}
+	}
+	for _, r := range c.unexpected {
+		failures = append(failures, "unexpected request: "+r)
+	}
+
+	if len(failures) > 0 {
+		t.Errorf("stub expectations are not met:\n\t%s", strings.Join(failures, "\n\t"))
+	}
+}
+
+func (c *StubServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e := c.match(r, body)
+	if e == nil {
+		http.Error(w, fmt.Sprintf("no expectation matches %s %s", r.Method, r.URL.RequestURI()), http.StatusNotImplemented)
+		return
+	}
+
+	if e.err != nil {
+		http.Error(w, e.err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for key, values := range e.replyHeader {
+		w.Header()[key] = append([]string{}, values...)
+	}
+	w.WriteHeader(e.status)
+	w.Write(e.replyBody)
+}
+
+// Returns the first expectation that matches the request and is not exhausted.
+// If all matched expectations are exhausted, the last one is called again, and Verify reports it.
+func (c *StubServer) match(r *http.Request, body []byte) *Expectation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var exhausted *Expectation
+	for _, e := range c.expectations {
+		if !e.matches(r, body) {
+			continue
+		}
+		if e.times >= 0 && e.calls >= e.times {
+			exhausted = e
+			continue
+		}
+
+		e.calls++
+		return e
+	}
+
+	if exhausted != nil {
+		exhausted.calls++
+		return exhausted
+	}
+
+	c.unexpected = append(c.unexpected, fmt.Sprintf("%s %s", r.Method, r.URL.RequestURI()))
+	return nil
+}
+
+// Expect the query param value
+func (c *Expectation) Query(key string, value string) *Expectation {
+	c.query.Add(key, value)
+	return c
+}
+
+// Expect the header value
+func (c *Expectation) Header(key string, value string) *Expectation {
+	c.header.Add(key, value)
+	return c
+}
+
+// Expect the body
+func (c *Expectation) Body(body string) *Expectation {
+	c.body = &body
+	return c
+}
+
+// Expect the json body.
+// It is compared semantically, and options such as JsonSubset can be used.
+func (c *Expectation) Json(obj any, opts ...JsonOption) *Expectation {
+	c.json = obj
+	c.jsonOpt = opts
+	return c
+}
+
+// Expect the request n times
+func (c *Expectation) Times(n int) *Expectation {
+	c.times = n
+	return c
+}
+
+// Expect the request any number of times, including never
+func (c *Expectation) AnyTimes() *Expectation {
+	c.times = -1
+	return c
+}
+
+// Reply with the status and the body
+func (c *Expectation) Reply(status int, body string) *Expectation {
+	c.status = status
+	c.replyBody = []byte(body)
+	return c
+}
+
+// Reply with the status and the json body
+func (c *Expectation) ReplyJson(status int, obj any) *Expectation {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		c.err = err
+	}
+
+	c.status = status
+	c.replyBody = b
+	c.replyHeader.Set("Content-Type", "application/json")
+	return c
+}
+
+// Add the header to the reply
+func (c *Expectation) ReplyHeader(key string, value string) *Expectation {
+	c.replyHeader.Add(key, value)
+	return c
+}
+
+// Returns the number of received requests
+func (c *Expectation) Calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.calls
+}
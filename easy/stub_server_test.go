@@ -0,0 +1,140 @@
+package easy_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cateiru/go-http-easy-test/v2/easy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStubServer(t *testing.T) {
+	t.Run("reply", func(t *testing.T) {
+		s := easy.StartStubServer(t)
+
+		s.Expect(http.MethodGet, "/users/{id}").
+			Header("Authorization", "Bearer token").
+			ReplyJson(http.StatusOK, JsonData{Nya: "aaaa"})
+		s.Expect(http.MethodPost, "/users").
+			Json(JsonData{Nya: "bbbb"}).
+			ReplyHeader("Location", "/users/2").
+			Reply(http.StatusCreated, "created")
+
+		resp := s.GetOK(t, "/users/1", easy.WithHeader("Authorization", "Bearer token"))
+		resp.EqHeader(t, "Content-Type", "application/json")
+		resp.EqJson(t, JsonData{Nya: "aaaa"})
+
+		resp = s.PostJson(t, "/users", JsonData{Nya: "bbbb"})
+		resp.Status(t, http.StatusCreated)
+		resp.EqHeader(t, "Location", "/users/2")
+		resp.EqBody(t, "created")
+	})
+
+	t.Run("query and body", func(t *testing.T) {
+		s := easy.StartStubServer(t)
+
+		page1 := s.Expect(http.MethodGet, "/items").Query("page", "1").Reply(http.StatusOK, "page 1")
+		s.Expect(http.MethodGet, "/items").Reply(http.StatusOK, "all").AnyTimes()
+		s.Expect(http.MethodPost, "/echo").Body("hello").Reply(http.StatusOK, "hello")
+
+		s.GetOK(t, "/items?page=1&size=10").EqBody(t, "page 1")
+		s.GetOK(t, "/items").EqBody(t, "all")
+		s.GetOK(t, "/items?page=2").EqBody(t, "all")
+		s.PostString(t, "/echo", "text/plain", "hello").EqBody(t, "hello")
+
+		require.Equal(t, 1, page1.Calls())
+	})
+
+	t.Run("times", func(t *testing.T) {
+		s := easy.StartStubServer(t)
+
+		s.Expect(http.MethodGet, "/retry").Times(2).Reply(http.StatusServiceUnavailable, "")
+		s.Expect(http.MethodGet, "/retry").Reply(http.StatusOK, "ok")
+
+		s.Get(t, "/retry").Status(t, http.StatusServiceUnavailable)
+		s.Get(t, "/retry").Status(t, http.StatusServiceUnavailable)
+		s.GetOK(t, "/retry").EqBody(t, "ok")
+	})
+
+	t.Run("path pattern", func(t *testing.T) {
+		s := easy.StartStubServer(t)
+
+		s.Expect(http.MethodGet, "/static/*").AnyTimes()
+
+		s.GetOK(t, "/static/css/style.css")
+		s.GetOK(t, "/static/")
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		s := easy.StartStubTLSServer(t)
+
+		s.Expect(http.MethodPost, "/form").Body(url.Values{"a": {"b"}}.Encode())
+
+		s.PostForm(t, "/form", url.Values{"a": {"b"}}).Ok(t)
+	})
+}
+
+func TestStubServerVerify(t *testing.T) {
+	t.Run("not called", func(t *testing.T) {
+		s := easy.NewStubServer()
+		defer s.Close()
+
+		s.Expect(http.MethodGet, "/users/{id}").Header("X-Api-Key", "key")
+
+		message := expectFail(t, s.Verify)
+		require.Contains(t, message, "GET /users/{id} header(X-Api-Key: key): expected 1 call(s), got 0")
+	})
+
+	t.Run("called too many times", func(t *testing.T) {
+		s := easy.NewStubServer()
+		defer s.Close()
+
+		s.Expect(http.MethodGet, "/").Reply(http.StatusOK, "ok")
+
+		s.GetOK(t, "/").EqBody(t, "ok")
+		s.GetOK(t, "/").EqBody(t, "ok")
+
+		message := expectFail(t, s.Verify)
+		require.Contains(t, message, "GET /: expected 1 call(s), got 2")
+	})
+
+	t.Run("unexpected request", func(t *testing.T) {
+		s := easy.NewStubServer()
+		defer s.Close()
+
+		s.Expect(http.MethodGet, "/users/{id}").AnyTimes()
+
+		s.Get(t, "/users/1/posts?page=1").Status(t, http.StatusNotImplemented)
+		s.Get(t, "/users/").Status(t, http.StatusNotImplemented)
+		s.Post(t, "/users/1", "text/plain", nil).Status(t, http.StatusNotImplemented)
+
+		message := expectFail(t, s.Verify)
+		require.Contains(t, message, "unexpected request: GET /users/1/posts?page=1")
+		require.Contains(t, message, "unexpected request: GET /users/")
+		require.Contains(t, message, "unexpected request: POST /users/1")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		s := easy.NewStubServer()
+		defer s.Close()
+
+		s.Expect(http.MethodPost, "/").Json(map[string]any{"a": 1}, easy.JsonSubset())
+
+		s.PostJson(t, "/", map[string]any{"a": 2}).Status(t, http.StatusNotImplemented)
+		s.PostJson(t, "/", map[string]any{"a": 1, "b": 2}).Ok(t)
+
+		message := expectFail(t, s.Verify)
+		require.Contains(t, message, "unexpected request: POST /")
+		require.NotContains(t, message, "expected 1 call(s)")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		s := easy.NewStubServer()
+		defer s.Close()
+
+		s.Expect(http.MethodGet, "/").AnyTimes()
+
+		s.Verify(t)
+	})
+}
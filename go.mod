@@ -5,6 +5,8 @@ go 1.20
 require (
 	github.com/labstack/echo/v4 v4.9.0
 	github.com/stretchr/testify v1.8.0
+	golang.org/x/net v0.0.0-20220926192436-02166a98028e
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -16,8 +18,6 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.1 // indirect
 	golang.org/x/crypto v0.0.0-20220926161630-eccd6366d1be // indirect
-	golang.org/x/net v0.0.0-20220926192436-02166a98028e // indirect
 	golang.org/x/sys v0.0.0-20220926163933-8cfa568d3c25 // indirect
 	golang.org/x/text v0.3.7 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )